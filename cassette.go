@@ -0,0 +1,96 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+
+	http_internal "github.com/next-trace/scg-test-kit/internal/http"
+)
+
+// Cassette is an http.RoundTripper that records outbound calls to a file or replays
+// them; see WithCassette.
+type Cassette = http_internal.Cassette
+
+// CassetteOption configures a Cassette.
+type CassetteOption = http_internal.CassetteOption
+
+// CassetteMode selects whether a cassette records or replays.
+type CassetteMode = http_internal.CassetteMode
+
+const (
+	// CassetteOnce replays the cassette if its file exists and records it otherwise.
+	CassetteOnce = http_internal.ModeOnce
+	// CassetteReplay only replays; requests without a recorded interaction fail.
+	CassetteReplay = http_internal.ModeReplay
+	// CassetteRecord sends every request upstream and overwrites the cassette.
+	CassetteRecord = http_internal.ModeRecord
+)
+
+// CassetteModeEnv overrides the mode of every cassette: "once", "replay" or "record".
+const CassetteModeEnv = http_internal.CassetteModeEnv
+
+// Fields for CassetteMatchOn.
+const (
+	MatchMethod = http_internal.MatchMethod
+	MatchURL    = http_internal.MatchURL
+	MatchPath   = http_internal.MatchPath
+	MatchBody   = http_internal.MatchBody
+)
+
+// WithCassetteMode sets the cassette mode; the default is CassetteOnce.
+func WithCassetteMode(mode CassetteMode) CassetteOption { return http_internal.WithMode(mode) }
+
+// CassetteUpstream sets the transport used while recording, e.g. the client
+// transport of a local stand-in; the default is http.DefaultTransport.
+func CassetteUpstream(rt http.RoundTripper) CassetteOption { return http_internal.Upstream(rt) }
+
+// CassetteMatchOn sets the fields a request must share with a recorded interaction.
+// The default is MatchMethod and MatchURL.
+func CassetteMatchOn(fields ...string) CassetteOption { return http_internal.MatchOn(fields...) }
+
+// CassetteMatchHeaders additionally requires the named request headers to be equal.
+func CassetteMatchHeaders(names ...string) CassetteOption {
+	return http_internal.MatchHeaders(names...)
+}
+
+// RedactHeaders replaces the named header values before a cassette is written.
+func RedactHeaders(names ...string) CassetteOption { return http_internal.RedactHeaders(names...) }
+
+// RedactBody rewrites bodies before a cassette is written and before matching.
+func RedactBody(fn func(body string) string) CassetteOption { return http_internal.RedactBody(fn) }
+
+// WithCassette registers a cassette stored at path under name. Give the code under
+// test the cassette as its transport:
+//
+//	h := testkit.New(t, testkit.WithCassette("github", "testdata/cassettes/github.json",
+//		testkit.RedactHeaders("Authorization")))
+//	client := &http.Client{Transport: testkit.CassetteFor(h, "github")}
+//
+// Recorded interactions are written when the harness cleans up; requests that had
+// no recorded interaction during replay fail the test.
+func WithCassette(name, path string, opts ...CassetteOption) Option {
+	return func(h *Harness) {
+		h.T().Helper()
+		c, err := http_internal.NewCassette(path, opts...)
+		if err != nil {
+			h.T().Fatalf("cassette %s: %v", name, err)
+			return
+		}
+		if err := h.AddResource(name, c, c.Save); err != nil {
+			h.T().Fatalf("resource %s: %v", name, err)
+			return
+		}
+		h.AddCheck(func() error {
+			if err := c.Verify(); err != nil {
+				return fmt.Errorf("cassette %s: %w", name, err)
+			}
+			return nil
+		})
+	}
+}
+
+// CassetteFor returns the cassette registered under name, failing the test if there is none.
+func CassetteFor(h *Harness, name string) *Cassette {
+	h.T().Helper()
+	return MustResource(h, NewKey[*Cassette](name))
+}
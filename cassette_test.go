@@ -0,0 +1,53 @@
+package testkit
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	http_internal "github.com/next-trace/scg-test-kit/internal/http"
+)
+
+func TestWithCassette(t *testing.T) {
+	t.Setenv(CassetteModeEnv, "")
+	path := filepath.Join(t.TempDir(), "upstream.json")
+
+	t.Run("Record", func(t *testing.T) {
+		upstream := New(t, WithHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("hello " + r.URL.Query().Get("name")))
+		})))
+		srv, _ := lookupHTTPServer(t, upstream)
+
+		h := New(t, WithCassette("api", path, CassetteUpstream(srv.Client().Transport), CassetteMatchOn(MatchMethod, MatchPath)))
+		c := CassetteFor(h, "api")
+		if !c.Recording() {
+			t.Fatal("expected a missing cassette to record")
+		}
+		resp, err := c.Client().Get(srv.BaseURL() + "/greet?name=alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		h := New(t, WithCassette("api", path, WithCassetteMode(CassetteReplay), CassetteMatchOn(MatchMethod, MatchPath)))
+		got := http_internal.NewClient(t, "http://api.invalid", CassetteFor(h, "api").Client()).Get("/greet?name=alice").Do().Text()
+		if got != "hello alice" {
+			t.Errorf("expected replayed body, got %q", got)
+		}
+	})
+
+	t.Run("UnmatchedFailsAtCleanup", func(t *testing.T) {
+		mockT := &errorfTB{TB: t}
+		h := New(mockT, WithCassette("api", path, WithCassetteMode(CassetteReplay)))
+		if _, err := CassetteFor(h, "api").Client().Get("http://api.invalid/greet"); err == nil {
+			t.Error("expected unmatched request to fail")
+		}
+		h.Cleanup()
+		if len(mockT.errors) != 1 || !strings.Contains(mockT.errors[0], "cassette api: 1 request(s) had no recorded interaction") {
+			t.Errorf("expected unmatched request to be reported, got %q", mockT.errors)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package testkit
+
+import (
+	"context"
+	"time"
+
+	"github.com/next-trace/scg-test-kit/internal/clock"
+)
+
+// ClockResourceName is the name used to store the clock in harness resources.
+const ClockResourceName = "Clock"
+
+// clockWaitTimeout bounds WaitForClockWaiters.
+const clockWaitTimeout = 5 * time.Second
+
+// Clock is the time source injected into code under test; see WithFakeClock.
+type Clock = clock.Clock
+
+// Timer is a single event created by Clock.NewTimer or Clock.AfterFunc.
+type Timer = clock.Timer
+
+// Ticker delivers the time at intervals; see Clock.NewTicker.
+type Ticker = clock.Ticker
+
+// FakeClock is a Clock that only moves when the test calls Advance or Set.
+type FakeClock = clock.Fake
+
+// RealClock returns a Clock backed by the time package.
+func RealClock() Clock {
+	return clock.Real()
+}
+
+// WithClock registers c as the harness clock.
+func WithClock(c Clock) Option {
+	return func(h *Harness) {
+		h.SetResource(ClockResourceName, c, nil)
+	}
+}
+
+// WithFakeClock registers a FakeClock set to start as the harness clock. A zero start
+// uses a fixed date, so tests do not depend on when they run:
+//
+//	h := testkit.New(t, testkit.WithFakeClock(time.Time{}))
+//	cache := NewCache(testkit.ClockFor(h), time.Minute)
+//	testkit.FakeClockFor(h).Advance(2 * time.Minute) // entries expire, timers fire in order
+func WithFakeClock(start time.Time) Option {
+	if start.IsZero() {
+		start = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return func(h *Harness) {
+		h.SetResource(ClockResourceName, clock.NewFake(start), nil)
+	}
+}
+
+// ClockFor returns the harness clock, or the real clock if none was registered.
+func ClockFor(h *Harness) Clock {
+	if c, ok := Resource[Clock](h, ClockResourceName); ok {
+		return c
+	}
+	return clock.Real()
+}
+
+// FakeClockFor returns the clock registered with WithFakeClock, failing the test if there is none.
+func FakeClockFor(h *Harness) *FakeClock {
+	h.T().Helper()
+	return MustResource(h, NewKey[*FakeClock](ClockResourceName))
+}
+
+// WaitForClockWaiters blocks until at least n goroutines are blocked in Sleep or
+// waiting on After of the fake clock, so that advancing it wakes them. Timers and
+// tickers do not count. It fails the test if that does not happen within 5s.
+func WaitForClockWaiters(h *Harness, n int) {
+	t := h.T()
+	t.Helper()
+	fake := FakeClockFor(h)
+	if fake == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(h.Context(), clockWaitTimeout)
+	defer cancel()
+	if err := fake.BlockUntil(ctx, n); err != nil {
+		t.Fatalf("fake clock: %d waiters expected, %d blocked: %v", n, fake.Waiters(), err)
+	}
+}
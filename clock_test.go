@@ -0,0 +1,61 @@
+package testkit
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithFakeClock(t *testing.T) {
+	start := time.Date(2030, time.June, 1, 12, 0, 0, 0, time.UTC)
+	h := New(t, WithFakeClock(start))
+	c := ClockFor(h)
+	if !c.Now().Equal(start) {
+		t.Fatalf("expected fake time %v, got %v", start, c.Now())
+	}
+
+	var wg sync.WaitGroup
+	woke := make(chan time.Time, 2)
+	for _, d := range []time.Duration{time.Minute, time.Hour} {
+		wg.Go(func() {
+			c.Sleep(d)
+			woke <- c.Now()
+		})
+	}
+	WaitForClockWaiters(h, 2)
+
+	FakeClockFor(h).Advance(time.Hour)
+	wg.Wait()
+	if got := <-woke; !got.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected sleepers to observe the advanced time, got %v", got)
+	}
+
+	sub := Sub(h, t)
+	if ClockFor(sub) != c {
+		t.Error("expected child harness to share the clock")
+	}
+}
+
+func TestClockFor(t *testing.T) {
+	t.Run("RealByDefault", func(t *testing.T) {
+		h := New(t)
+		if d := time.Since(ClockFor(h).Now()); d < 0 || d > time.Minute {
+			t.Errorf("expected real clock, off by %s", d)
+		}
+	})
+
+	t.Run("ZeroStart", func(t *testing.T) {
+		h := New(t, WithFakeClock(time.Time{}))
+		if got := ClockFor(h).Now(); got.IsZero() || got.Year() != 2024 {
+			t.Errorf("expected fixed default date, got %v", got)
+		}
+	})
+
+	t.Run("FakeMissing", func(t *testing.T) {
+		mtb := &fatalfTB{TB: t}
+		h := New(mtb, WithClock(RealClock()))
+		if FakeClockFor(h) != nil || len(mtb.messages) != 1 {
+			t.Errorf("expected wrong clock type to fail, got %v", mtb.messages)
+		}
+	})
+}
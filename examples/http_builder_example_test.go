@@ -0,0 +1,39 @@
+package examples
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/next-trace/scg-test-kit"
+)
+
+func TestHTTPBuilderExample(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": r.URL.Query().Get("id")})
+	})
+
+	h := testkit.NewBrowserHarness(t, handler)
+
+	resp := testkit.HTTP(h).Put("/items").
+		Query("id", "42").
+		BearerToken("token-123").
+		JSON(map[string]string{"name": "widget"}).
+		Do()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var item struct {
+		ID string `json:"id"`
+	}
+	resp.DecodeJSON(&item)
+	if item.ID != "42" {
+		t.Errorf("expected 42, got %s", item.ID)
+	}
+}
@@ -0,0 +1,58 @@
+package testkit
+
+import (
+	"testing"
+)
+
+type testUser struct {
+	ID    int
+	Name  string
+	Email string
+	Role  string
+	Admin bool
+}
+
+var testUsers = NewFactory(func(r *Rand, n int) testUser {
+	return testUser{ID: n, Name: r.Name(), Email: r.Email(), Role: "member"}
+}).
+	Trait("admin", func(u *testUser) { u.Role = "admin"; u.Admin = true }).
+	Trait("named", func(u *testUser) { u.Name = "Trait" })
+
+func TestFactory(t *testing.T) {
+	t.Run("DefaultsAndSequence", func(t *testing.T) {
+		h := New(t)
+		users := testUsers.BuildList(h, 3)
+		for i, u := range users {
+			if u.ID != i+1 || u.Name == "" || u.Email == "" || u.Role != "member" {
+				t.Errorf("unexpected user %d: %+v", i, u)
+			}
+		}
+		// Derived factories share the sequence
+		if u := testUsers.With("admin").Build(h); u.ID != 4 {
+			t.Errorf("expected sequence to continue, got %d", u.ID)
+		}
+
+		if again := testUsers.BuildList(New(t), 3); again[2] != users[2] {
+			t.Errorf("expected reproducible values, got %+v and %+v", again[2], users[2])
+		}
+	})
+
+	t.Run("TraitsThenOverrides", func(t *testing.T) {
+		h := New(t)
+		u := testUsers.With("admin", "named").Build(h, func(u *testUser) { u.Role = "owner" })
+		if !u.Admin || u.Name != "Trait" || u.Role != "owner" {
+			t.Errorf("expected traits then overrides, got %+v", u)
+		}
+		if plain := testUsers.Build(h); plain.Admin {
+			t.Error("expected With not to modify the base factory")
+		}
+	})
+
+	t.Run("UnknownTrait", func(t *testing.T) {
+		mtb := &fatalfTB{TB: t}
+		h := New(mtb)
+		if u := testUsers.With("ghost").Build(h); u != (testUser{}) || len(mtb.messages) != 1 {
+			t.Errorf("expected unknown trait to fail, got %v", mtb.messages)
+		}
+	})
+}
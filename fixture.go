@@ -0,0 +1,104 @@
+package testkit
+
+import (
+	"context"
+	"os"
+	"reflect"
+
+	"github.com/next-trace/scg-test-kit/internal/fixture"
+)
+
+// FixturesResourceName is the name used to store loaded fixtures in harness resources.
+const FixturesResourceName = "Fixtures"
+
+// FixtureOption configures WithFixtures.
+type FixtureOption func(*fixture.Loader)
+
+// FixtureSet holds the fixtures loaded by WithFixtures.
+type FixtureSet = fixture.Set
+
+// FixtureRecord is a loaded fixture with its id, kind and source file.
+type FixtureRecord = fixture.Record
+
+// FixtureKind decodes the records listed under kind into T, strictly, and passes each
+// to sink, e.g. a repository's insert function. sink may assign fields such as
+// generated keys, which other records can then reference; it may be nil.
+func FixtureKind[T any](kind string, sink func(ctx context.Context, record *T) error) FixtureOption {
+	return func(l *fixture.Loader) {
+		l.Register(kind, fixture.NewKind(sink))
+	}
+}
+
+// FixtureDecoder loads files with extension ext, e.g. ".yaml", with decode, which has
+// the signature of json.Unmarshal. Only .json files are read by default; this keeps
+// the toolkit free of a YAML dependency:
+//
+//	testkit.FixtureDecoder(".yaml", yaml.Unmarshal)
+func FixtureDecoder(ext string, decode func(data []byte, v any) error) FixtureOption {
+	return func(l *fixture.Loader) {
+		l.Decoder(ext, decode)
+	}
+}
+
+// WithFixtures loads the fixture files in dir, including subdirectories. Each file
+// maps kinds to records by symbolic id, and a string "@id" or "@id.field" refers to
+// another record as it was after its sink ran:
+//
+//	{
+//	  "users":  {"alice": {"name": "Alice"}},
+//	  "orders": {"first": {"user_id": "@alice.id", "total": 10}}
+//	}
+//
+//	h := testkit.New(t, testkit.WithFixtures("testdata/fixtures",
+//		testkit.FixtureKind("users", repo.InsertUser),
+//		testkit.FixtureKind("orders", repo.InsertOrder),
+//	))
+//	alice := testkit.Fixture[User](h, "alice")
+//
+// Records are handed to their sinks so that every record follows the ones it
+// references. Unknown kinds, fields or references, duplicate ids, cycles and sink
+// errors fail the test.
+func WithFixtures(dir string, opts ...FixtureOption) Option {
+	return func(h *Harness) {
+		t := h.T()
+		t.Helper()
+		loader := fixture.NewLoader()
+		for _, opt := range opts {
+			opt(loader)
+		}
+		set, err := loader.Load(h.Context(), os.DirFS(dir))
+		if err != nil {
+			t.Fatalf("fixtures %s: %v", dir, err)
+			return
+		}
+		h.SetResource(FixturesResourceName, set, nil)
+	}
+}
+
+// Fixtures returns the fixtures loaded by WithFixtures, failing the test if there are none.
+func Fixtures(h *Harness) *FixtureSet {
+	h.T().Helper()
+	return MustResource(h, NewKey[*FixtureSet](FixturesResourceName))
+}
+
+// Fixture returns the loaded record with the given id, failing the test if it is
+// missing or not of type T.
+func Fixture[T any](h *Harness, id string) *T {
+	t := h.T()
+	t.Helper()
+	set := Fixtures(h)
+	if set == nil {
+		return nil
+	}
+	record, ok := set.Get(id)
+	if !ok {
+		t.Fatalf("fixture %q is missing", id)
+		return nil
+	}
+	value, ok := record.Value.(*T)
+	if !ok {
+		t.Fatalf("fixture %q has wrong type: want *%s, got %T", id, reflect.TypeFor[T](), record.Value)
+		return nil
+	}
+	return value
+}
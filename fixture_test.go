@@ -0,0 +1,81 @@
+package testkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fixtureUser struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type fixtureOrder struct {
+	UserID int `json:"user_id"`
+	Total  int `json:"total"`
+}
+
+func TestWithFixtures(t *testing.T) {
+	var users []*fixtureUser
+	var orders []*fixtureOrder
+	h := New(t, WithFixtures("testdata/fixtures",
+		FixtureKind("users", func(_ context.Context, u *fixtureUser) error {
+			users = append(users, u)
+			u.ID = 100 + len(users)
+			return nil
+		}),
+		FixtureKind("orders", func(_ context.Context, o *fixtureOrder) error {
+			orders = append(orders, o)
+			return nil
+		}),
+	))
+
+	alice := Fixture[fixtureUser](h, "alice")
+	if alice == nil || alice.Name != "Alice" || alice.ID == 0 {
+		t.Fatalf("unexpected alice %+v", alice)
+	}
+	if first := Fixture[fixtureOrder](h, "first"); first.UserID != alice.ID || first.Total != 10 {
+		t.Errorf("expected order to reference the inserted user, got %+v", first)
+	}
+	if len(users) != 2 || len(orders) != 2 || len(Fixtures(h).IDs()) != 4 {
+		t.Errorf("expected every record to reach its sink, got %d users and %d orders", len(users), len(orders))
+	}
+
+	t.Run("Lookup", func(t *testing.T) {
+		mtb := &fatalfTB{TB: t}
+		sub := Sub(h, mtb)
+		if Fixture[fixtureUser](sub, "ghost") != nil || Fixture[fixtureUser](sub, "first") != nil || len(mtb.messages) != 2 {
+			t.Errorf("expected missing and mistyped fixtures to fail, got %v", mtb.messages)
+		}
+	})
+}
+
+func TestWithFixtures_Decoder(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "users.conf"), []byte(`{"users": {"ada": {"name": "Ada"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	h := New(t, WithFixtures(dir,
+		FixtureKind[fixtureUser]("users", nil),
+		FixtureDecoder(".conf", json.Unmarshal),
+	))
+	if ada := Fixture[fixtureUser](h, "ada"); ada.Name != "Ada" {
+		t.Errorf("unexpected record %+v", ada)
+	}
+}
+
+func TestWithFixtures_Error(t *testing.T) {
+	mtb := &fatalfTB{TB: t}
+	New(mtb, WithFixtures("testdata/fixtures",
+		FixtureKind("users", func(context.Context, *fixtureUser) error { return errors.New("db down") }),
+		FixtureKind[fixtureOrder]("orders", nil),
+	))
+	if len(mtb.messages) == 0 || mtb.messages[0] != "fixtures testdata/fixtures: users.json: alice: db down" {
+		t.Errorf("expected sink error to fail the test, got %v", mtb.messages)
+	}
+}
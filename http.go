@@ -0,0 +1,180 @@
+package testkit
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/next-trace/scg-test-kit/internal/dom"
+	http_internal "github.com/next-trace/scg-test-kit/internal/http"
+)
+
+// HTTPClient builds requests against the harness HTTP server.
+type HTTPClient = http_internal.Client
+
+// Request is an HTTP request under construction; finish it with Do.
+type Request = http_internal.Request
+
+// Response is a captured HTTP response with a fully read body.
+type Response = http_internal.Response
+
+// HTTPServerOption configures the server started by WithHTTPServer.
+type HTTPServerOption = http_internal.ServerOption
+
+// InProcess makes WithHTTPServer dispatch requests straight into the handler through
+// an in-memory transport instead of a loopback listener. HTTP, BrowserSession and the
+// other request helpers behave the same in both modes:
+//
+//	h := testkit.NewUnitHarness(t, testkit.WithHTTPServer(handler, testkit.InProcess()))
+func InProcess() HTTPServerOption { return http_internal.InProcess() }
+
+// TLS makes WithHTTPServer serve over HTTPS; the harness client trusts its certificate.
+func TLS() HTTPServerOption { return http_internal.TLS() }
+
+// HTTP2 makes WithHTTPServer serve over HTTPS with HTTP/2, which the harness client negotiates.
+func HTTP2() HTTPServerOption { return http_internal.HTTP2() }
+
+// MutualTLS makes WithHTTPServer serve over HTTPS with a certificate issued by a
+// throwaway CA and verify client certificates issued by it. Use HTTPAs to send
+// requests with the certificate of an identity; HTTP sends none, so handlers can
+// be tested against anonymous clients too.
+func MutualTLS() HTTPServerOption { return http_internal.MutualTLS() }
+
+// Document is a parsed HTML response; see Response.HTML.
+type Document = http_internal.Document
+
+// Form is an HTML form found in a Document, ready to be filled in and submitted.
+type Form = http_internal.Form
+
+// Element is an element of a Document.
+type Element = dom.Node
+
+// httpServer is the capability exposed by the HTTPServer resource.
+type httpServer interface {
+	BaseURL() string
+	Client() *http.Client
+}
+
+// lookupHTTPServer returns the HTTPServer resource of h, failing t if it is unavailable.
+func lookupHTTPServer(t testing.TB, h *Harness) (httpServer, bool) {
+	t.Helper()
+	val, ok := h.Resource(HTTPResourceName)
+	if !ok {
+		t.Fatal("HTTPServer resource not available")
+		return nil, false
+	}
+	srv, ok := val.(httpServer)
+	if !ok {
+		t.Fatal("HTTPServer resource does not implement required interface")
+		return nil, false
+	}
+	return srv, true
+}
+
+// HTTP returns a request builder rooted at the HTTPServer resource:
+//
+//	resp := testkit.HTTP(h).Put("/items/1").
+//		BearerToken(token).
+//		Query("dry_run", "true").
+//		JSON(item).
+//		Do()
+//
+// Failures are reported to h.T().
+func HTTP(h *Harness) *HTTPClient {
+	t := h.T()
+	t.Helper()
+	srv, ok := lookupHTTPServer(t, h)
+	if !ok {
+		return nil
+	}
+	return http_internal.NewClient(t, srv.BaseURL(), srv.Client())
+}
+
+// Session is a browser-like HTTP client with its own cookie jar.
+type Session = http_internal.Session
+
+// SessionOption configures a Session created by BrowserSession.
+type SessionOption = http_internal.SessionOption
+
+// MaxRedirects sets how many redirects a session follows per request, failing on
+// redirect n+1; 0 disables following. The default is 10.
+func MaxRedirects(n int) SessionOption { return http_internal.MaxRedirects(n) }
+
+// NoRedirects makes a session return 3xx responses instead of following them.
+func NoRedirects() SessionOption { return http_internal.NoRedirects() }
+
+// SessionLogin runs login when the session is first created, so later calls to
+// BrowserSession with the same name reuse the logged-in session.
+func SessionLogin(login func(s *Session)) SessionOption { return http_internal.WithLogin(login) }
+
+// sessionResourcePrefix namespaces sessions among the harness resources.
+const sessionResourcePrefix = "browser-session:"
+
+// BrowserSession returns the session called name, creating it against the HTTPServer
+// resource on first use. Each session has its own cookie jar, so several sessions
+// model several users:
+//
+//	admin := testkit.BrowserSession(h, "admin", testkit.SessionLogin(func(s *testkit.Session) {
+//		s.Post("/login").Form(url.Values{"user": {"admin"}}).Do().ExpectStatus(http.StatusOK)
+//	}))
+//	admin.Get("/dashboard").Do().ExpectStatus(http.StatusOK)
+//
+// Options only apply when the session is created. Sessions created by a parent
+// harness are visible to its children, with failures reported to the child's test.
+func BrowserSession(h *Harness, name string, opts ...SessionOption) *Session {
+	t := h.T()
+	t.Helper()
+	key := sessionResourcePrefix + name
+	if _, ok := h.Resource(key); !ok {
+		srv, ok := lookupHTTPServer(t, h)
+		if !ok {
+			return nil
+		}
+		// A provider makes concurrent first uses log in once, without a lock held
+		// during login, so a login may open other sessions
+		if err := h.EnsureProvider(key, func(*Harness) (any, func() error, error) {
+			return http_internal.NewSession(t, name, srv.BaseURL(), srv.Client(), opts...), nil, nil
+		}); err != nil {
+			t.Fatalf("session %s: %v", name, err)
+			return nil
+		}
+	}
+
+	val, ok := h.Resource(key)
+	if !ok {
+		return nil
+	}
+	s, ok := val.(*Session)
+	if !ok {
+		t.Fatalf("resource %s is %T, not a session", key, val)
+		return nil
+	}
+	return s.WithT(t)
+}
+
+// identityServer is implemented by servers started with MutualTLS.
+type identityServer interface {
+	ClientFor(identity string) (*http.Client, error)
+}
+
+// HTTPAs returns a request builder like HTTP whose client presents a certificate
+// for identity, minted on first use by the CA of a MutualTLS server. The identity
+// is the certificate's common name.
+func HTTPAs(h *Harness, identity string) *HTTPClient {
+	t := h.T()
+	t.Helper()
+	srv, ok := lookupHTTPServer(t, h)
+	if !ok {
+		return nil
+	}
+	ids, ok := srv.(identityServer)
+	if !ok {
+		t.Fatal("HTTPServer resource does not support client certificates")
+		return nil
+	}
+	client, err := ids.ClientFor(identity)
+	if err != nil {
+		t.Fatalf("client certificate for %s: %v", identity, err)
+		return nil
+	}
+	return http_internal.NewClient(t, srv.BaseURL(), client)
+}
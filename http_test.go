@@ -0,0 +1,222 @@
+package testkit
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHTTP(t *testing.T) {
+	h := NewBrowserHarness(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(r.URL.RawQuery))
+	}))
+
+	resp := HTTP(h).Delete("/items/1").Query("force", "true").Do()
+	if resp.StatusCode != http.StatusAccepted || resp.Header.Get("X-Method") != http.MethodDelete {
+		t.Errorf("unexpected response: %d %v", resp.StatusCode, resp.Header)
+	}
+	if resp.Text() != "force=true" {
+		t.Errorf("expected query to be sent, got %q", resp.Text())
+	}
+}
+
+func TestHTTP_NoServer(t *testing.T) {
+	mockT := &mockTB{TB: t}
+	if HTTP(New(mockT)) != nil || !mockT.failed {
+		t.Error("expected HTTP to fail when HTTPServer is missing")
+	}
+}
+
+func TestBrowserSession(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "user", Value: r.FormValue("user"), Path: "/"})
+		http.SetCookie(w, &http.Cookie{Name: "csrf", Value: "token-" + r.FormValue("user"), Path: "/"})
+		http.Redirect(w, r, "/me", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("user")
+		if err != nil {
+			http.Error(w, "anonymous", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(c.Value))
+	})
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		if csrf, err := r.Cookie("csrf"); err != nil || r.FormValue("csrf") != csrf.Value {
+			http.Error(w, "bad csrf token", http.StatusForbidden)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "user", Path: "/", MaxAge: -1})
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := NewBrowserHarness(t, mux)
+
+	logins := 0
+	login := func(user string) SessionOption {
+		return SessionLogin(func(s *Session) {
+			logins++
+			resp := s.Post("/login").Form(url.Values{"user": {user}}).Do().ExpectStatus(http.StatusOK)
+			if chain := resp.RedirectChain(); len(chain) != 2 {
+				t.Errorf("expected login to redirect once, got %v", chain)
+			}
+		})
+	}
+
+	alice := BrowserSession(h, "alice", login("alice"))
+	bob := BrowserSession(h, "bob", login("bob"))
+	alice.Get("/me").Do().ExpectStatus(http.StatusOK)
+	if got := bob.Get("/me").Do().Text(); got != "bob" {
+		t.Errorf("expected sessions to be isolated, got %q", got)
+	}
+
+	t.Run("Reuse", func(t *testing.T) {
+		sub := Sub(h, t)
+		again := BrowserSession(sub, "alice", login("alice"))
+		if got := again.Get("/me").Do().Text(); got != "alice" {
+			t.Errorf("expected reused session, got %q", got)
+		}
+	})
+	if logins != 2 {
+		t.Errorf("expected each session to log in once, got %d logins", logins)
+	}
+
+	t.Run("LoginOpensSession", func(t *testing.T) {
+		sub := Sub(h, t)
+		var carol *Session
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			carol = BrowserSession(sub, "carol", SessionLogin(func(*Session) {
+				BrowserSession(sub, "dave", login("dave"))
+			}))
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected a login to be able to open another session")
+		}
+		if carol == nil || BrowserSession(sub, "dave").Get("/me").Do().Text() != "dave" {
+			t.Error("expected both sessions to be created")
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		sub := Sub(h, t)
+		var mu sync.Mutex
+		erinLogins := 0
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Go(func() {
+				BrowserSession(sub, "erin", SessionLogin(func(*Session) {
+					mu.Lock()
+					erinLogins++
+					mu.Unlock()
+				}))
+			})
+		}
+		wg.Wait()
+		if erinLogins != 1 {
+			t.Errorf("expected concurrent first uses to log in once, got %d", erinLogins)
+		}
+	})
+
+	alice.Post("/logout").Form(url.Values{"csrf": {"wrong"}}).Do().ExpectStatus(http.StatusForbidden)
+	alice.Post("/logout").Form(url.Values{"csrf": {alice.Cookie("csrf").Value}}).Do().ExpectStatus(http.StatusNoContent)
+	alice.Get("/me").Do().ExpectStatus(http.StatusUnauthorized)
+	bob.Get("/me").Do().ExpectStatus(http.StatusOK)
+}
+
+func TestBrowserSession_Form(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/new", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "csrf", Value: "abc", Path: "/"})
+		_, _ = w.Write([]byte(`<form class="item" action="/items" method="post">
+			<input type="hidden" name="csrf" value="abc"><input name="title"></form>`))
+	})
+	mux.HandleFunc("POST /items", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("csrf"); err != nil || c.Value != r.FormValue("csrf") {
+			http.Error(w, "bad csrf token", http.StatusForbidden)
+			return
+		}
+		http.Redirect(w, r, "/items/1?title="+url.QueryEscape(r.FormValue("title")), http.StatusSeeOther)
+	})
+	mux.HandleFunc("GET /items/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<h1 id="title">` + r.URL.Query().Get("title") + `</h1>`))
+	})
+	h := NewBrowserHarness(t, mux)
+
+	s := BrowserSession(h, "editor")
+	form := s.Get("/items/new").Do().HTML().Form("form.item")
+	title := form.Set("title", "Widget").Submit().ExpectStatus(http.StatusOK).HTML().ByID("title")
+	if title.Text() != "Widget" {
+		t.Errorf("expected submitted title, got %q", title.Text())
+	}
+}
+
+func TestWithHTTPServer_Modes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "user", Value: r.FormValue("user"), Path: "/"})
+		http.Redirect(w, r, "/me", http.StatusFound)
+	})
+	mux.HandleFunc("GET /me", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("user")
+		if err != nil {
+			http.Error(w, "anonymous", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"user":"` + c.Value + `"}`))
+	})
+
+	for name, opts := range map[string][]HTTPServerOption{
+		"Socket":    nil,
+		"InProcess": {InProcess()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := NewUnitHarness(t, WithHTTPServer(mux, opts...), WithFDLeakCheck())
+			s := BrowserSession(h, "alice")
+			s.Post("/login").Form(url.Values{"user": {"alice"}}).Do().
+				ExpectStatus(http.StatusOK).
+				ExpectJSONPath("$.user", "alice")
+			HTTP(h).Get("/me").Do().ExpectStatus(http.StatusUnauthorized)
+		})
+	}
+}
+
+func TestWithHTTPServer_TLS(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			http.Error(w, "client certificate required", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(r.Proto + " " + r.TLS.PeerCertificates[0].Subject.CommonName))
+	})
+
+	t.Run("MutualTLS", func(t *testing.T) {
+		h := New(t, WithHTTPServer(handler, MutualTLS()))
+		HTTP(h).Get("/").Do().ExpectStatus(http.StatusUnauthorized)
+		if got := HTTPAs(h, "svc-billing").Get("/").Do().ExpectStatus(http.StatusOK).Text(); got != "HTTP/1.1 svc-billing" {
+			t.Errorf("expected client identity, got %q", got)
+		}
+	})
+
+	t.Run("HTTP2", func(t *testing.T) {
+		h := New(t, WithHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Proto))
+		}), HTTP2()))
+		if got := HTTP(h).Get("/").Do().Text(); got != "HTTP/2.0" {
+			t.Errorf("expected HTTP/2, got %q", got)
+		}
+
+		mockT := &mockTB{TB: t}
+		if HTTPAs(New(mockT, WithHTTPServer(handler, TLS())), "alice") != nil || !mockT.failed {
+			t.Error("expected HTTPAs to require mutual TLS")
+		}
+	})
+}
@@ -0,0 +1,290 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when Advance or Set is called. Timers,
+// tickers and sleepers due by the new time fire in deadline order, ties in the order
+// they were scheduled, and each observes the clock at its own deadline.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	seq     uint64
+	timers  map[*fakeTimer]struct{}
+	waiters int           // pending timers created by Sleep and After
+	changed chan struct{} // closed and replaced whenever timers changes
+}
+
+// NewFake returns a Fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{
+		now:     start,
+		timers:  make(map[*fakeTimer]struct{}),
+		changed: make(chan struct{}),
+	}
+}
+
+// Now returns the fake time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that receives the fake time once it has advanced by d.
+// Until then it is counted by Waiters.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1), waiter: true}
+	f.schedule(t, d)
+	return t.c
+}
+
+// NewTimer returns a Timer that fires once the fake time has advanced by d.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	f.schedule(t, d)
+	return t
+}
+
+// AfterFunc calls fn once the fake time has advanced by d. fn runs on the goroutine
+// calling Advance or Set, so its effects are visible when they return.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	t := &fakeTimer{clock: f, fn: fn}
+	f.schedule(t, d)
+	return t
+}
+
+// NewTicker returns a Ticker that fires every d of fake time. As with time.Ticker,
+// ticks are dropped if the receiver falls behind.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1), period: d}
+	f.schedule(t, d)
+	return (*fakeTicker)(t)
+}
+
+// Sleep blocks until the fake time has advanced by d. Sleeping goroutines are counted
+// by Waiters, so tests can call BlockUntil before advancing.
+func (f *Fake) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	<-f.After(d)
+}
+
+// Advance moves the fake time forward by d, firing everything due on the way.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	f.mu.Unlock()
+	f.advanceTo(target)
+}
+
+// Set moves the fake time to t. Moving forward fires everything due by t; moving
+// backwards fires nothing, and pending deadlines stay where they were.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	if t.Before(f.now) {
+		f.now = t
+		f.mu.Unlock()
+		return
+	}
+	f.mu.Unlock()
+	f.advanceTo(t)
+}
+
+// Waiters returns the number of goroutines blocked in Sleep plus the number of
+// channels returned by After that have not fired yet. Timers, tickers and AfterFunc
+// callbacks are not counted, so a background ticker does not look like a sleeper.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.waiters
+}
+
+// Pending returns the number of scheduled timers, tickers, AfterFunc callbacks
+// and waiters.
+func (f *Fake) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// BlockUntil waits until Waiters reports at least n, which tells a test that the
+// goroutines under test are parked on the clock and that it is safe to advance.
+// It returns ctx.Err() if ctx is done first.
+func (f *Fake) BlockUntil(ctx context.Context, n int) error {
+	for {
+		f.mu.Lock()
+		count, changed := f.waiters, f.changed
+		f.mu.Unlock()
+		if count >= n {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (f *Fake) advanceTo(target time.Time) {
+	for {
+		f.mu.Lock()
+		next := f.nextLocked(target)
+		if next == nil {
+			if target.After(f.now) {
+				f.now = target
+			}
+			f.mu.Unlock()
+			return
+		}
+		if next.deadline.After(f.now) {
+			f.now = next.deadline
+		}
+		if next.period > 0 {
+			next.deadline = next.deadline.Add(next.period)
+			next.seq = f.nextSeq()
+		} else {
+			f.removeLocked(next)
+		}
+		// Values are sent under the lock, in the same step that takes the timer off
+		// the schedule, so a concurrent Stop either cancels the timer or drains C
+		if next.fn == nil {
+			next.sendLocked(f.now)
+			f.mu.Unlock()
+			continue
+		}
+		f.mu.Unlock()
+		next.fn()
+	}
+}
+
+// nextLocked returns the earliest waiter due by target.
+func (f *Fake) nextLocked(target time.Time) *fakeTimer {
+	var next *fakeTimer
+	for t := range f.timers {
+		if t.deadline.After(target) {
+			continue
+		}
+		if next == nil || t.deadline.Before(next.deadline) ||
+			(t.deadline.Equal(next.deadline) && t.seq < next.seq) {
+			next = t
+		}
+	}
+	return next
+}
+
+func (f *Fake) schedule(t *fakeTimer, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t.deadline = f.now.Add(d)
+	if d > 0 {
+		t.seq = f.nextSeq()
+		f.timers[t] = struct{}{}
+		if t.waiter {
+			f.waiters++
+		}
+		f.notifyLocked()
+		return
+	}
+	// Matches the time package: a timer that is already due fires right away
+	if t.fn != nil {
+		go t.fn()
+	} else {
+		t.sendLocked(f.now)
+	}
+}
+
+// stop removes t, drains its channel and reports whether it was pending. Both
+// happen under the lock, so no value fired before stop returns is left in C.
+func (f *Fake) stop(t *fakeTimer) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, pending := f.timers[t]
+	if pending {
+		f.removeLocked(t)
+	}
+	if t.c != nil {
+		select {
+		case <-t.c:
+		default:
+		}
+	}
+	return pending
+}
+
+func (f *Fake) removeLocked(t *fakeTimer) {
+	delete(f.timers, t)
+	if t.waiter {
+		f.waiters--
+	}
+	f.notifyLocked()
+}
+
+func (f *Fake) notifyLocked() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *Fake) nextSeq() uint64 {
+	f.seq++
+	return f.seq
+}
+
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	fn       func()
+	waiter   bool          // created by Sleep or After, counted by Fake.Waiters
+	period   time.Duration // non-zero for tickers, guarded by clock.mu
+	deadline time.Time     // guarded by clock.mu
+	seq      uint64        // guarded by clock.mu
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+// Stop cancels the timer. As with the time package since Go 1.23, a stale value
+// is never received from C after Stop or Reset returns.
+func (t *fakeTimer) Stop() bool {
+	return t.clock.stop(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	active := t.Stop()
+	t.clock.schedule(t, d)
+	return active
+}
+
+// sendLocked delivers now on C, dropping it if C is full. It must be called with
+// clock.mu held.
+func (t *fakeTimer) sendLocked(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+type fakeTicker fakeTimer
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {
+	(*fakeTimer)(t).Stop()
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+	t.clock.mu.Lock()
+	t.period = d
+	t.clock.mu.Unlock()
+	(*fakeTimer)(t).Reset(d)
+}
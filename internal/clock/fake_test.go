@@ -0,0 +1,225 @@
+package clock
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFake_Advance(t *testing.T) {
+	t.Run("FiresInDeadlineOrder", func(t *testing.T) {
+		c := NewFake(epoch)
+		var fired []string
+		var seen []time.Time
+		record := func(name string) func() {
+			return func() {
+				fired = append(fired, name)
+				seen = append(seen, c.Now())
+			}
+		}
+		c.AfterFunc(3*time.Second, record("c"))
+		c.AfterFunc(time.Second, record("a"))
+		c.AfterFunc(2*time.Second, record("b1"))
+		c.AfterFunc(2*time.Second, record("b2"))
+		c.AfterFunc(time.Minute, record("late"))
+
+		c.Advance(5 * time.Second)
+
+		if want := []string{"a", "b1", "b2", "c"}; !reflect.DeepEqual(fired, want) {
+			t.Errorf("expected %v, got %v", want, fired)
+		}
+		if !seen[0].Equal(epoch.Add(time.Second)) || !seen[3].Equal(epoch.Add(3*time.Second)) {
+			t.Errorf("expected callbacks to observe their deadline, got %v", seen)
+		}
+		if got := c.Now(); !got.Equal(epoch.Add(5 * time.Second)) {
+			t.Errorf("expected clock at +5s, got %v", got)
+		}
+		if n := c.Pending(); n != 1 {
+			t.Errorf("expected the late timer to remain pending, got %d", n)
+		}
+	})
+
+	t.Run("TimersScheduledWhileFiring", func(t *testing.T) {
+		c := NewFake(epoch)
+		var fired []time.Duration
+		c.AfterFunc(time.Second, func() {
+			fired = append(fired, c.Now().Sub(epoch))
+			c.AfterFunc(time.Second, func() { fired = append(fired, c.Now().Sub(epoch)) })
+		})
+		c.Advance(3 * time.Second)
+		if want := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(fired, want) {
+			t.Errorf("expected chained timer to fire within the same advance, got %v", fired)
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		c := NewFake(epoch)
+		ch := c.After(time.Hour)
+		c.Set(epoch.Add(-time.Hour))
+		c.Set(epoch.Add(30 * time.Minute))
+		select {
+		case <-ch:
+			t.Error("expected timer not to fire before its deadline")
+		default:
+		}
+		c.Set(epoch.Add(2 * time.Hour))
+		if got := <-ch; !got.Equal(epoch.Add(time.Hour)) {
+			t.Errorf("expected timer to fire at its deadline, got %v", got)
+		}
+	})
+}
+
+func TestFake_Timer(t *testing.T) {
+	c := NewFake(epoch)
+	timer := c.NewTimer(time.Second)
+	if !timer.Stop() || timer.Stop() {
+		t.Error("expected Stop to report whether the timer was pending")
+	}
+	c.Advance(time.Second)
+	select {
+	case <-timer.C():
+		t.Error("expected stopped timer not to fire")
+	default:
+	}
+
+	c.Advance(time.Second)
+	if timer.Reset(time.Second) {
+		t.Error("expected Reset of a stopped timer to report false")
+	}
+	c.Advance(time.Second)
+	if got := <-timer.C(); !got.Equal(epoch.Add(3 * time.Second)) {
+		t.Errorf("expected reset timer to fire at +3s, got %v", got)
+	}
+
+	timer.Reset(time.Second)
+	c.Advance(time.Second)
+	timer.Reset(time.Second)
+	select {
+	case <-timer.C():
+		t.Error("expected Reset to drop the stale value")
+	default:
+	}
+}
+
+func TestFake_StopRacingAdvance(t *testing.T) {
+	for range 200 {
+		c := NewFake(epoch)
+		timer := c.NewTimer(time.Second)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Advance(time.Second)
+		}()
+		timer.Stop()
+		<-done
+		select {
+		case <-timer.C():
+			t.Fatal("expected no value on C after Stop returned")
+		default:
+		}
+	}
+}
+
+func TestFake_Ticker(t *testing.T) {
+	c := NewFake(epoch)
+	ticker := c.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	c.Advance(time.Second)
+	if got := <-ticker.C(); !got.Equal(epoch.Add(time.Second)) {
+		t.Errorf("expected tick at +1s, got %v", got)
+	}
+	// Ticks are dropped while nobody receives
+	c.Advance(3 * time.Second)
+	if got := <-ticker.C(); !got.Equal(epoch.Add(2 * time.Second)) {
+		t.Errorf("expected the first missed tick to be kept, got %v", got)
+	}
+	select {
+	case got := <-ticker.C():
+		t.Errorf("expected later ticks to be dropped, got %v", got)
+	default:
+	}
+
+	ticker.Reset(time.Minute)
+	c.Advance(time.Second)
+	select {
+	case <-ticker.C():
+		t.Error("expected reset ticker to use the new period")
+	default:
+	}
+	c.Advance(time.Minute)
+	if got := <-ticker.C(); !got.Equal(epoch.Add(4*time.Second + time.Minute)) {
+		t.Errorf("expected tick one minute after reset, got %v", got)
+	}
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	c := NewFake(epoch)
+	// Background timers are pending but nobody is blocked on them
+	defer c.NewTicker(time.Second).Stop()
+	c.NewTimer(time.Hour)
+	c.AfterFunc(time.Hour, func() {})
+	if n := c.Waiters(); n != 0 {
+		t.Fatalf("expected timers and tickers not to count as waiters, got %d", n)
+	}
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Go(func() { c.Sleep(time.Minute) })
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.BlockUntil(ctx, 3); err != nil {
+		t.Fatalf("expected sleepers to park on the clock: %v", err)
+	}
+	c.Advance(time.Minute)
+	wg.Wait()
+	if n := c.Waiters(); n != 0 {
+		t.Errorf("expected no waiters after sleepers woke, got %d", n)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	if err := c.BlockUntil(short, 1); err != context.DeadlineExceeded {
+		t.Errorf("expected BlockUntil to honour the context, got %v", err)
+	}
+}
+
+func TestFake_ImmediateDeadline(t *testing.T) {
+	c := NewFake(epoch)
+	if got := <-c.After(0); !got.Equal(epoch) {
+		t.Errorf("expected After(0) to fire at once, got %v", got)
+	}
+	c.Sleep(-time.Second)
+
+	done := make(chan struct{})
+	c.AfterFunc(0, func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected AfterFunc(0) to run")
+	}
+}
+
+func TestReal(t *testing.T) {
+	c := Real()
+	before := time.Now()
+	c.Sleep(time.Millisecond)
+	if !c.Now().After(before) {
+		t.Error("expected real clock to move")
+	}
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+	ticker := c.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+	<-c.After(time.Millisecond)
+	done := make(chan struct{})
+	c.AfterFunc(time.Millisecond, func() { close(done) })
+	<-done
+}
@@ -0,0 +1,49 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	t.Run("Equal", func(t *testing.T) {
+		if d := Unified("a", "b", "x\ny\n", "x\ny\n"); d != "" {
+			t.Errorf("expected no diff, got %q", d)
+		}
+	})
+
+	t.Run("Change", func(t *testing.T) {
+		a := "{\n  \"id\": 1,\n  \"name\": \"old\"\n}"
+		b := "{\n  \"id\": 1,\n  \"name\": \"new\"\n}"
+		want := strings.Join([]string{
+			"--- expected",
+			"+++ actual",
+			"@@ -1,4 +1,4 @@",
+			" {",
+			`   "id": 1,`,
+			`-  "name": "old"`,
+			`+  "name": "new"`,
+			" }",
+		}, "\n")
+		if d := Unified("expected", "actual", a, b); d != want {
+			t.Errorf("unexpected diff:\n%s\nwant:\n%s", d, want)
+		}
+	})
+
+	t.Run("SeparateHunks", func(t *testing.T) {
+		var a, b []string
+		for i := range 20 {
+			line := string(rune('a' + i))
+			a = append(a, line)
+			b = append(b, line)
+		}
+		b[1], b[18] = "B", "S"
+		d := Unified("a", "b", strings.Join(a, "\n"), strings.Join(b, "\n"))
+		if strings.Count(d, "@@ -") != 2 {
+			t.Errorf("expected two hunks, got:\n%s", d)
+		}
+		if !strings.Contains(d, "@@ -1,5 +1,5 @@") || !strings.Contains(d, "@@ -16,5 +16,5 @@") {
+			t.Errorf("unexpected hunk headers:\n%s", d)
+		}
+	})
+}
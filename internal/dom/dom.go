@@ -0,0 +1,321 @@
+// Package dom parses HTML into a simple node tree that tests can query with
+// CSS-like selectors. It is forgiving rather than spec-complete: it handles the
+// markup of server-rendered pages, not every quirk browsers recover from.
+package dom
+
+import (
+	"html"
+	"strings"
+)
+
+// NodeType identifies the kind of a Node.
+type NodeType int
+
+const (
+	// DocumentNode is the root of a parsed document.
+	DocumentNode NodeType = iota
+	// ElementNode is an HTML element.
+	ElementNode
+	// TextNode holds unescaped character data.
+	TextNode
+)
+
+// Attr is an attribute of an element. Keys are lower case.
+type Attr struct {
+	Key, Val string
+}
+
+// Node is a node of the document tree.
+type Node struct {
+	Type     NodeType
+	Tag      string // lower-case element name, empty for other nodes
+	Attrs    []Attr
+	Data     string // text of text nodes
+	Parent   *Node
+	Children []*Node
+}
+
+// Attr returns the value of the named attribute.
+func (n *Node) Attr(key string) (string, bool) {
+	key = strings.ToLower(key)
+	for _, a := range n.Attrs {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// AttrOr returns the value of the named attribute, or def if it is absent.
+func (n *Node) AttrOr(key, def string) string {
+	if val, ok := n.Attr(key); ok {
+		return val
+	}
+	return def
+}
+
+// HasClass reports whether the class attribute contains class.
+func (n *Node) HasClass(class string) bool {
+	for _, c := range strings.Fields(n.AttrOr("class", "")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Text returns the text content of n and its descendants with runs of whitespace
+// collapsed to a single space.
+func (n *Node) Text() string {
+	var b strings.Builder
+	n.walk(func(c *Node) {
+		if c.Type == TextNode {
+			b.WriteString(c.Data)
+			b.WriteByte(' ')
+		}
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// RawText returns the text content of n and its descendants unchanged.
+func (n *Node) RawText() string {
+	var b strings.Builder
+	n.walk(func(c *Node) {
+		if c.Type == TextNode {
+			b.WriteString(c.Data)
+		}
+	})
+	return b.String()
+}
+
+// walk calls fn for n and its descendants in document order.
+func (n *Node) walk(fn func(*Node)) {
+	fn(n)
+	for _, c := range n.Children {
+		c.walk(fn)
+	}
+}
+
+// voidElements never have content or an end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true,
+	"track": true, "wbr": true,
+}
+
+// rawTextElements contain text up to their end tag without nested markup.
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// impliedEnd lists elements that are closed by an open sibling of the same kind,
+// as in <li>a<li>b or <option>a<option>b.
+var impliedEnd = map[string]bool{
+	"li": true, "option": true, "p": true, "tr": true, "td": true, "th": true, "dt": true, "dd": true,
+}
+
+// Parse builds a document tree from src. It never fails: malformed markup is
+// recovered from by ignoring stray end tags and closing open elements at EOF.
+func Parse(src string) *Node {
+	p := parser{src: src, doc: &Node{Type: DocumentNode}}
+	p.stack = []*Node{p.doc}
+	p.parse()
+	return p.doc
+}
+
+type parser struct {
+	src   string
+	pos   int
+	doc   *Node
+	stack []*Node
+}
+
+func (p *parser) top() *Node { return p.stack[len(p.stack)-1] }
+
+func (p *parser) append(n *Node) {
+	parent := p.top()
+	n.Parent = parent
+	parent.Children = append(parent.Children, n)
+}
+
+func (p *parser) text(s string) {
+	if s != "" {
+		p.append(&Node{Type: TextNode, Data: html.UnescapeString(s)})
+	}
+}
+
+func (p *parser) parse() {
+	for p.pos < len(p.src) {
+		rest := p.src[p.pos:]
+		lt := strings.IndexByte(rest, '<')
+		if lt < 0 {
+			p.text(rest)
+			return
+		}
+		p.text(rest[:lt])
+		p.pos += lt
+		rest = p.src[p.pos:]
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			p.skipPast("-->")
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			p.skipPast(">")
+		case strings.HasPrefix(rest, "</"):
+			p.endTag()
+		case len(rest) > 1 && isLetter(rest[1]):
+			p.startTag()
+		default:
+			p.text("<")
+			p.pos++
+		}
+	}
+}
+
+func (p *parser) skipPast(marker string) {
+	end := strings.Index(p.src[p.pos:], marker)
+	if end < 0 {
+		p.pos = len(p.src)
+		return
+	}
+	p.pos += end + len(marker)
+}
+
+func (p *parser) endTag() {
+	p.pos += 2
+	name := strings.ToLower(p.name())
+	p.skipPast(">")
+	for i := len(p.stack) - 1; i > 0; i-- {
+		if p.stack[i].Tag == name {
+			p.stack = p.stack[:i]
+			return
+		}
+	}
+}
+
+func (p *parser) startTag() {
+	p.pos++
+	n := &Node{Type: ElementNode, Tag: strings.ToLower(p.name())}
+	selfClosing := p.attrs(n)
+
+	if impliedEnd[n.Tag] && p.top().Tag == n.Tag {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+	p.append(n)
+	if voidElements[n.Tag] || selfClosing {
+		return
+	}
+	if rawTextElements[n.Tag] {
+		rest := p.src[p.pos:]
+		end := indexEndTag(rest, n.Tag)
+		if content := rest[:end]; content != "" {
+			if n.Tag == "textarea" || n.Tag == "title" {
+				content = html.UnescapeString(content)
+			}
+			n.Children = []*Node{{Type: TextNode, Data: content, Parent: n}}
+		}
+		p.pos += end
+		p.skipPast(">")
+		return
+	}
+	p.stack = append(p.stack, n)
+}
+
+// indexEndTag returns the index of the first "</tag" in s that ends the tag name,
+// i.e. is followed by '>', '/', whitespace or the end of s, matching tag without
+// regard to ASCII case, or len(s) if there is none. It compares the original bytes
+// so that offsets stay valid whatever s contains.
+func indexEndTag(s, tag string) int {
+	for i := 0; ; {
+		j := strings.Index(s[i:], "</")
+		if j < 0 {
+			return len(s)
+		}
+		i += j
+		if end := i + 2 + len(tag); end <= len(s) && strings.EqualFold(s[i+2:end], tag) &&
+			(end == len(s) || strings.IndexByte(">/ \t\n\r\f", s[end]) >= 0) {
+			return i
+		}
+		i += 2
+	}
+}
+
+// attrs parses the attributes of a start tag up to its closing '>'
+// and reports whether the tag was self-closing.
+func (p *parser) attrs(n *Node) bool {
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return false
+		}
+		switch p.src[p.pos] {
+		case '>':
+			p.pos++
+			return false
+		case '/':
+			p.pos++
+			if p.pos < len(p.src) && p.src[p.pos] == '>' {
+				p.pos++
+				return true
+			}
+			continue
+		}
+
+		key := strings.ToLower(p.name())
+		if key == "" {
+			// Not an attribute name; skip the offending byte
+			p.pos++
+			continue
+		}
+		p.skipSpace()
+		val := ""
+		if p.pos < len(p.src) && p.src[p.pos] == '=' {
+			p.pos++
+			p.skipSpace()
+			val = html.UnescapeString(p.value())
+		}
+		n.Attrs = append(n.Attrs, Attr{Key: key, Val: val})
+	}
+}
+
+// name reads a tag or attribute name.
+func (p *parser) name() string {
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(" \t\n\r\f/>=", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// value reads a quoted or unquoted attribute value.
+func (p *parser) value() string {
+	if p.pos >= len(p.src) {
+		return ""
+	}
+	if q := p.src[p.pos]; q == '"' || q == '\'' {
+		end := strings.IndexByte(p.src[p.pos+1:], q)
+		if end < 0 {
+			val := p.src[p.pos+1:]
+			p.pos = len(p.src)
+			return val
+		}
+		val := p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return val
+	}
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(" \t\n\r\f>", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\n\r\f", rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
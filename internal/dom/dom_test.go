@@ -0,0 +1,147 @@
+package dom
+
+import (
+	"strings"
+	"testing"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head><title>Admin &amp; users</title>
+<script>if (a < b && c > d) { document.write("<p>no</p>") }</script></head>
+<body>
+<!-- <form id="commented"> -->
+<ul id=menu class="nav main">
+  <li><a href="/users">Users</a>
+  <li class=active><a href='/settings?tab=1&amp;x=2'>Settings</a>
+</ul>
+<form id="edit" action="/users/1" method="post">
+  <input type="hidden" name="csrf" value="t0k3n">
+  <input name=email value="a@example.com"/>
+  <select name="role"><option>user<option selected value="admin">Administrator</select>
+  <textarea name="bio">1 &lt; 2</textarea>
+  <br>
+  <button type="submit">Save</button>
+</form>
+<p>stray</span> end</p>
+</body>
+</html>`
+
+func find(t *testing.T, doc *Node, sel string) []*Node {
+	t.Helper()
+	s, err := Compile(sel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc.Find(s)
+}
+
+func TestParse(t *testing.T) {
+	doc := Parse(page)
+
+	if title := find(t, doc, "title"); len(title) != 1 || title[0].Text() != "Admin & users" {
+		t.Errorf("unexpected title: %v", title)
+	}
+	if script := find(t, doc, "script"); len(script) != 1 || !strings.Contains(script[0].RawText(), "<p>no</p>") {
+		t.Error("expected script content to be raw text")
+	}
+	if p := find(t, doc, "p"); len(p) != 1 || p[0].Text() != "stray end" {
+		t.Errorf("expected stray end tag to be ignored, got %v", p)
+	}
+	if forms := find(t, doc, "form"); len(forms) != 1 {
+		t.Errorf("expected commented form to be skipped, got %d forms", len(forms))
+	}
+
+	items := find(t, doc, "ul > li")
+	if len(items) != 2 {
+		t.Fatalf("expected implied </li> to produce 2 items, got %d", len(items))
+	}
+	if href := find(t, doc, "li.active a")[0].AttrOr("href", ""); href != "/settings?tab=1&x=2" {
+		t.Errorf("expected unescaped href, got %q", href)
+	}
+
+	options := find(t, doc, "select[name=role] option")
+	if len(options) != 2 || options[0].Text() != "user" {
+		t.Fatalf("expected implied </option>, got %d options", len(options))
+	}
+	if _, ok := options[1].Attr("selected"); !ok {
+		t.Error("expected boolean attribute to be present")
+	}
+	if bio := doc.ByID("edit").Find(mustCompile(t, "textarea"))[0].RawText(); bio != "1 < 2" {
+		t.Errorf("expected unescaped textarea, got %q", bio)
+	}
+	if button := find(t, doc, "form#edit > button"); len(button) != 1 || button[0].Parent.Tag != "form" {
+		t.Error("expected void elements not to swallow siblings")
+	}
+}
+
+func TestParse_RawTextEnd(t *testing.T) {
+	invalid := strings.Repeat("\xff", 10)
+	if script := find(t, Parse("<script>"+invalid+"</SCRIPT><p>after</p>"), "script"); len(script) != 1 || script[0].RawText() != invalid {
+		t.Errorf("expected invalid UTF-8 to be kept as raw text, got %v", script)
+	}
+
+	// Lower-casing these changes their byte length
+	for _, text := range []string{strings.Repeat("Ⱥ", 8), strings.Repeat("İ", 3)} {
+		doc := Parse("<title>" + text + "</title><p>x</p>")
+		if title := find(t, doc, "title"); len(title) != 1 || title[0].Text() != text {
+			t.Errorf("expected title %q, got %v", text, title)
+		}
+		if p := find(t, doc, "p"); len(p) != 1 || p[0].Text() != "x" {
+			t.Errorf("expected element after %q title to be parsed, got %v", text, p)
+		}
+	}
+
+	if script := find(t, Parse("<script>a</scrip"), "script"); script[0].RawText() != "a</scrip" {
+		t.Errorf("expected truncated end tag to stay raw text, got %q", script[0].RawText())
+	}
+
+	// Only a complete tag name ends the element
+	for src, want := range map[string]string{
+		"<title>a</titlex>b</title>":    "a</titlex>b",
+		"<script>a</scripts>b</script>": "a</scripts>b",
+		"<script>a</script >b":          "a",
+		"<script>a</script\n>b":         "a",
+		"<script>a</script/>b":          "a",
+		"<script>a</script":             "a",
+	} {
+		tag := src[1:strings.IndexByte(src, '>')]
+		if el := find(t, Parse(src), tag); len(el) != 1 || el[0].RawText() != want {
+			t.Errorf("%q: expected raw text %q, got %v", src, want, el)
+		}
+	}
+}
+
+func mustCompile(t *testing.T, sel string) *Selector {
+	t.Helper()
+	s, err := Compile(sel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSelector(t *testing.T) {
+	doc := Parse(page)
+	for sel, want := range map[string]int{
+		"input":                           2,
+		"*[name]":                         4,
+		"#menu.nav.main":                  1,
+		"ul#menu .active":                 1,
+		"body > a":                        0,
+		"body a":                          2,
+		`input[type="hidden"][name=csrf]`: 1,
+		"textarea, select, input":         4,
+		"form > select > option":          2,
+	} {
+		if got := len(find(t, doc, sel)); got != want {
+			t.Errorf("%s: expected %d matches, got %d", sel, want, got)
+		}
+	}
+
+	for _, sel := range []string{"", "a,", "> a", "a >", "#", "a.", "a[", "[=x]", "a:hover"} {
+		if _, err := Compile(sel); err == nil {
+			t.Errorf("%q: expected error", sel)
+		}
+	}
+}
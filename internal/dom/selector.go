@@ -0,0 +1,242 @@
+package dom
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Selector is a compiled CSS-like selector. The supported grammar is a comma
+// separated list of compound selectors joined by descendant (whitespace) or
+// child (>) combinators, where a compound selector is an optional tag name or *
+// followed by any number of #id, .class, [attr] and [attr=value] filters.
+type Selector struct {
+	source string
+	groups [][]step
+}
+
+// step is one compound selector and the combinator linking it to the previous one.
+type step struct {
+	child   bool // combined with the previous step by '>' rather than whitespace
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrFilter
+}
+
+type attrFilter struct {
+	key, val string
+	hasVal   bool
+}
+
+// Compile parses sel.
+func Compile(sel string) (*Selector, error) {
+	s := &Selector{source: sel}
+	for _, group := range splitOutsideBrackets(sel, ',') {
+		steps, err := compileGroup(group)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", sel, err)
+		}
+		s.groups = append(s.groups, steps)
+	}
+	return s, nil
+}
+
+// String returns the source of the selector.
+func (s *Selector) String() string {
+	return s.source
+}
+
+func compileGroup(group string) ([]step, error) {
+	var steps []step
+	child := false
+	rest := strings.TrimSpace(group)
+	if rest == "" {
+		return nil, fmt.Errorf("empty selector")
+	}
+	for rest != "" {
+		if rest[0] == '>' {
+			if child || len(steps) == 0 {
+				return nil, fmt.Errorf("misplaced '>'")
+			}
+			child = true
+			rest = strings.TrimSpace(rest[1:])
+			continue
+		}
+		st, n, err := compileStep(rest)
+		if err != nil {
+			return nil, err
+		}
+		st.child = child
+		steps = append(steps, st)
+		child = false
+		rest = strings.TrimSpace(rest[n:])
+	}
+	if child {
+		return nil, fmt.Errorf("dangling '>'")
+	}
+	return steps, nil
+}
+
+// compileStep parses a compound selector at the start of s and returns it with
+// the number of bytes consumed.
+func compileStep(s string) (step, int, error) {
+	var st step
+	i := 0
+	ident := func() string {
+		start := i
+		for i < len(s) && (isLetter(s[i]) || '0' <= s[i] && s[i] <= '9' || s[i] == '-' || s[i] == '_') {
+			i++
+		}
+		return s[start:i]
+	}
+
+	if i < len(s) && s[i] == '*' {
+		i++
+	} else {
+		st.tag = strings.ToLower(ident())
+	}
+	for i < len(s) {
+		switch s[i] {
+		case '#':
+			i++
+			if st.id = ident(); st.id == "" {
+				return st, 0, fmt.Errorf("missing id after '#'")
+			}
+		case '.':
+			i++
+			class := ident()
+			if class == "" {
+				return st, 0, fmt.Errorf("missing class after '.'")
+			}
+			st.classes = append(st.classes, class)
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return st, 0, fmt.Errorf("unterminated '['")
+			}
+			f, err := compileAttr(s[i+1 : i+end])
+			if err != nil {
+				return st, 0, err
+			}
+			st.attrs = append(st.attrs, f)
+			i += end + 1
+		case ' ', '\t', '\n', '>':
+			return st, i, nil
+		default:
+			return st, 0, fmt.Errorf("unexpected %q", s[i])
+		}
+	}
+	if i == 0 {
+		return st, 0, fmt.Errorf("empty selector")
+	}
+	return st, i, nil
+}
+
+func compileAttr(s string) (attrFilter, error) {
+	key, val, hasVal := strings.Cut(s, "=")
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" {
+		return attrFilter{}, fmt.Errorf("missing attribute name in [%s]", s)
+	}
+	val = strings.TrimSpace(val)
+	if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+		val = val[1 : len(val)-1]
+	}
+	return attrFilter{key: key, val: val, hasVal: hasVal}, nil
+}
+
+// splitOutsideBrackets splits s at sep, ignoring separators inside [...].
+func splitOutsideBrackets(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// Match reports whether n matches the selector.
+func (s *Selector) Match(n *Node) bool {
+	for _, steps := range s.groups {
+		if matchSteps(n, steps) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSteps matches n against the last step and its ancestors against the rest.
+func matchSteps(n *Node, steps []step) bool {
+	last := steps[len(steps)-1]
+	if !last.match(n) {
+		return false
+	}
+	if len(steps) == 1 {
+		return true
+	}
+	rest := steps[:len(steps)-1]
+	if last.child {
+		return n.Parent != nil && matchSteps(n.Parent, rest)
+	}
+	for a := n.Parent; a != nil; a = a.Parent {
+		if matchSteps(a, rest) {
+			return true
+		}
+	}
+	return false
+}
+
+func (st step) match(n *Node) bool {
+	if n.Type != ElementNode || st.tag != "" && n.Tag != st.tag {
+		return false
+	}
+	if st.id != "" && n.AttrOr("id", "") != st.id {
+		return false
+	}
+	for _, c := range st.classes {
+		if !n.HasClass(c) {
+			return false
+		}
+	}
+	for _, f := range st.attrs {
+		val, ok := n.Attr(f.key)
+		if !ok || f.hasVal && val != f.val {
+			return false
+		}
+	}
+	return true
+}
+
+// Find returns the descendants of n matching sel in document order.
+func (n *Node) Find(sel *Selector) []*Node {
+	var found []*Node
+	for _, c := range n.Children {
+		c.walk(func(d *Node) {
+			if sel.Match(d) {
+				found = append(found, d)
+			}
+		})
+	}
+	return found
+}
+
+// ByID returns the first element with the given id, or nil.
+func (n *Node) ByID(id string) *Node {
+	var found *Node
+	n.walk(func(d *Node) {
+		if found == nil && d.Type == ElementNode && d.AttrOr("id", "") == id {
+			found = d
+		}
+	})
+	return found
+}
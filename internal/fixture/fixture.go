@@ -0,0 +1,383 @@
+// Package fixture loads declarative fixture files, resolves references between their
+// records and hands the records to sinks in dependency order.
+//
+// A fixture file maps kinds to records by symbolic id:
+//
+//	{
+//	  "users":  {"alice": {"name": "Alice"}},
+//	  "orders": {"first": {"user_id": "@alice.id", "total": 10}}
+//	}
+//
+// A string "@id" is replaced by the loaded record id, and "@id.field.0" by a value
+// inside it, as the record marshals to JSON after its sink ran, so values assigned
+// by the sink, such as generated keys, can be referenced. "@@" escapes a literal "@".
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Decoder decodes the contents of a fixture file into v, like json.Unmarshal.
+type Decoder func(data []byte, v any) error
+
+// Kind decodes and stores the records of one kind.
+type Kind interface {
+	load(ctx context.Context, data []byte) (any, error)
+}
+
+type kind[T any] struct {
+	sink func(ctx context.Context, record *T) error
+}
+
+// NewKind returns a Kind whose records decode into T and are passed to sink, which
+// may be nil. The loaded record is the *T after sink returns.
+func NewKind[T any](sink func(ctx context.Context, record *T) error) Kind {
+	return kind[T]{sink: sink}
+}
+
+func (k kind[T]) load(ctx context.Context, data []byte) (any, error) {
+	record := new(T)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(record); err != nil {
+		return nil, fmt.Errorf("decode %T: %w", record, err)
+	}
+	if k.sink != nil {
+		if err := k.sink(ctx, record); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+// Loader loads fixture files for registered kinds.
+type Loader struct {
+	kinds    map[string]Kind
+	decoders map[string]Decoder
+}
+
+// NewLoader returns a Loader that reads .json files.
+func NewLoader() *Loader {
+	return &Loader{
+		kinds:    make(map[string]Kind),
+		decoders: map[string]Decoder{".json": decodeJSON},
+	}
+}
+
+// Register makes records under name load as k.
+func (l *Loader) Register(name string, k Kind) {
+	l.kinds[name] = k
+}
+
+// Decoder makes files with extension ext, e.g. ".yaml", load with dec.
+func (l *Loader) Decoder(ext string, dec Decoder) {
+	l.decoders[ext] = dec
+}
+
+// Record is a loaded fixture record.
+type Record struct {
+	ID    string
+	Kind  string
+	File  string
+	Value any // *T of the kind's type
+
+	raw  any      // decoded file contents, references unresolved
+	deps []string // ids referenced by raw
+	json any      // Value as generic JSON, for resolving references to it
+}
+
+// Set holds the records loaded by a Loader.
+type Set struct {
+	order   []string
+	records map[string]*Record
+}
+
+// Get returns the record with the given id.
+func (s *Set) Get(id string) (*Record, bool) {
+	r, ok := s.records[id]
+	return r, ok
+}
+
+// IDs returns the ids of all records in the order they were loaded.
+func (s *Set) IDs() []string {
+	return slices.Clone(s.order)
+}
+
+// Load reads every file in fsys with a known extension, in lexical order, and loads
+// its records so that each is handed to its sink after the records it references.
+// Ids must be unique across files.
+func (l *Loader) Load(ctx context.Context, fsys fs.FS) (*Set, error) {
+	set := &Set{records: make(map[string]*Record)}
+	var declared []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		dec, ok := l.decoders[path.Ext(name)]
+		if d.IsDir() || !ok {
+			return nil
+		}
+		ids, err := l.readFile(fsys, name, dec, set)
+		declared = append(declared, ids...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range declared {
+		r := set.records[id]
+		if err := collectRefs(r.raw, func(ref string) error {
+			if _, ok := set.records[ref]; !ok {
+				return fmt.Errorf("%s: %s: unknown reference @%s", r.File, id, ref)
+			}
+			r.deps = append(r.deps, ref)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	order, err := sortRecords(declared, set.records)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range order {
+		if err := l.loadRecord(ctx, set, set.records[id]); err != nil {
+			return nil, err
+		}
+		set.order = append(set.order, id)
+	}
+	return set, nil
+}
+
+// readFile adds the records of one file to set and returns their ids, sorted.
+func (l *Loader) readFile(fsys fs.FS, name string, dec Decoder, set *Set) ([]string, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	var contents any
+	if err := dec(data, &contents); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	byKind, ok := normalize(contents).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected an object of kinds, got %T", name, contents)
+	}
+
+	var ids []string
+	for _, kindName := range slices.Sorted(maps.Keys(byKind)) {
+		if _, ok := l.kinds[kindName]; !ok {
+			return nil, fmt.Errorf("%s: unknown fixture kind %q (registered: %s)",
+				name, kindName, strings.Join(slices.Sorted(maps.Keys(l.kinds)), ", "))
+		}
+		records, ok := byKind[kindName].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: %s: expected an object of records by id, got %T", name, kindName, byKind[kindName])
+		}
+		for _, id := range slices.Sorted(maps.Keys(records)) {
+			if prev, ok := set.records[id]; ok {
+				return nil, fmt.Errorf("%s: duplicate fixture id %q, also in %s", name, id, prev.File)
+			}
+			set.records[id] = &Record{ID: id, Kind: kindName, File: name, raw: records[id]}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (l *Loader) loadRecord(ctx context.Context, set *Set, r *Record) error {
+	resolved, err := resolve(r.raw, set.records)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", r.File, r.ID, err)
+	}
+	data, err := json.Marshal(resolved)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", r.File, r.ID, err)
+	}
+	value, err := l.kinds[r.Kind].load(ctx, data)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", r.File, r.ID, err)
+	}
+	r.Value = value
+
+	// Round-trip through JSON so references see the field names of the encoded record
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", r.File, r.ID, err)
+	}
+	return decodeJSON(encoded, &r.json)
+}
+
+// decodeJSON is json.Unmarshal keeping numbers exact, so large ids survive being
+// copied into referencing records.
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// sortRecords orders ids so that every record comes after the records it references.
+func sortRecords(ids []string, records map[string]*Record) ([]string, error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(ids))
+	order := make([]string, 0, len(ids))
+	var path []string
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case done:
+			return nil
+		case visiting:
+			cycle := append(slices.Clone(path[slices.Index(path, id):]), id)
+			return fmt.Errorf("fixture reference cycle: %s", strings.Join(cycle, " -> "))
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range records[id].deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		order = append(order, id)
+		return nil
+	}
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// parseRef splits a reference string into the record id and the path inside it.
+// ok is false for plain strings, including escaped "@@" ones.
+func parseRef(s string) (id string, fields []string, ok bool) {
+	if !strings.HasPrefix(s, "@") || strings.HasPrefix(s, "@@") {
+		return "", nil, false
+	}
+	parts := strings.Split(s[1:], ".")
+	return parts[0], parts[1:], true
+}
+
+func collectRefs(v any, visit func(id string) error) error {
+	switch v := v.(type) {
+	case string:
+		if id, _, ok := parseRef(v); ok {
+			return visit(id)
+		}
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			if err := collectRefs(v[key], visit); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := collectRefs(item, visit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve returns a copy of v with references replaced by the values they name.
+func resolve(v any, records map[string]*Record) (any, error) {
+	switch v := v.(type) {
+	case string:
+		if strings.HasPrefix(v, "@@") {
+			return v[1:], nil
+		}
+		id, fields, ok := parseRef(v)
+		if !ok {
+			return v, nil
+		}
+		value, err := lookupPath(records[id].json, fields)
+		if err != nil {
+			return nil, fmt.Errorf("reference %s: %w", v, err)
+		}
+		return value, nil
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			resolved, err := resolve(item, records)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			resolved, err := resolve(item, records)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+func lookupPath(v any, fields []string) (any, error) {
+	for i, field := range fields {
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[field]
+			if !ok {
+				return nil, fmt.Errorf("no field %q", strings.Join(fields[:i+1], "."))
+			}
+			v = next
+		case []any:
+			index, err := strconv.Atoi(field)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("no element %q", strings.Join(fields[:i+1], "."))
+			}
+			v = node[index]
+		default:
+			return nil, errors.New("cannot select " + strings.Join(fields[:i+1], ".") + " in a scalar")
+		}
+	}
+	return v, nil
+}
+
+// normalize converts map[any]any, as produced by some YAML decoders, to map[string]any.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[fmt.Sprint(key)] = normalize(item)
+		}
+		return out
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	}
+	return v
+}
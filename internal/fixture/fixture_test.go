@@ -0,0 +1,133 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type user struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type order struct {
+	UserID int64    `json:"user_id"`
+	Owner  user     `json:"owner"`
+	Tags   []string `json:"tags"`
+}
+
+func newLoader(inserted *[]string) *Loader {
+	var nextID int64 = 9007199254740993 // beyond float64 precision
+	l := NewLoader()
+	l.Register("users", NewKind(func(_ context.Context, u *user) error {
+		u.ID = nextID
+		nextID++
+		*inserted = append(*inserted, "user "+u.Name)
+		return nil
+	}))
+	l.Register("orders", NewKind(func(_ context.Context, o *order) error {
+		*inserted = append(*inserted, "order of "+o.Owner.Name)
+		return nil
+	}))
+	return l
+}
+
+func TestLoader_Load(t *testing.T) {
+	fsys := fstest.MapFS{
+		// Sorted before users.json, so its records are declared first
+		"a_orders.json": {Data: []byte(`{"orders": {"first": {
+			"user_id": "@bob.id", "owner": "@bob", "tags": ["@@literal", "@alice.name"]}}}`)},
+		"users.json":  {Data: []byte(`{"users": {"bob": {"name": "Bob"}, "alice": {"name": "Alice"}}}`)},
+		"README.md":   {Data: []byte("not a fixture")},
+		"nested/x.js": {Data: []byte("ignored")},
+	}
+	var inserted []string
+	set, err := newLoader(&inserted).Load(context.Background(), fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(inserted, ", "); got != "user Bob, user Alice, order of Bob" {
+		t.Errorf("expected dependencies to be inserted first, got %s", got)
+	}
+	if got := strings.Join(set.IDs(), ","); got != "bob,alice,first" {
+		t.Errorf("unexpected load order %s", got)
+	}
+
+	r, ok := set.Get("first")
+	if !ok || r.Kind != "orders" || r.File != "a_orders.json" {
+		t.Fatalf("unexpected record %+v", r)
+	}
+	o := r.Value.(*order)
+	bob, _ := set.Get("bob")
+	if o.UserID != bob.Value.(*user).ID || o.UserID != 9007199254740993 {
+		t.Errorf("expected generated id to be referenced exactly, got %d", o.UserID)
+	}
+	if o.Owner.Name != "Bob" || strings.Join(o.Tags, ",") != "@literal,Alice" {
+		t.Errorf("unexpected resolved order %+v", o)
+	}
+}
+
+func TestLoader_Decoder(t *testing.T) {
+	l := NewLoader()
+	l.Register("users", NewKind[user](nil))
+	// A decoder producing map[any]any, as some YAML decoders do
+	l.Decoder(".kv", func(data []byte, v any) error {
+		name, _ := strings.CutPrefix(strings.TrimSpace(string(data)), "name=")
+		*v.(*any) = map[any]any{"users": map[any]any{"ada": map[any]any{"name": name}}}
+		return nil
+	})
+	set, err := l.Load(context.Background(), fstest.MapFS{"users.kv": {Data: []byte("name=Ada")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := set.Get("ada"); !ok || r.Value.(*user).Name != "Ada" {
+		t.Errorf("expected record from custom decoder, got %+v", r)
+	}
+}
+
+func TestLoader_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		want  string
+	}{
+		{"UnknownKind", fstest.MapFS{"x.json": {Data: []byte(`{"pets": {"rex": {}}}`)}},
+			`x.json: unknown fixture kind "pets" (registered: orders, users)`},
+		{"UnknownField", fstest.MapFS{"x.json": {Data: []byte(`{"users": {"bob": {"nmae": "Bob"}}}`)}},
+			`x.json: bob: decode *fixture.user: json: unknown field "nmae"`},
+		{"UnknownRef", fstest.MapFS{"x.json": {Data: []byte(`{"orders": {"o": {"owner": "@ghost"}}}`)}},
+			"x.json: o: unknown reference @ghost"},
+		{"BadPath", fstest.MapFS{"x.json": {Data: []byte(`{"users": {"bob": {"name": "Bob"}}, "orders": {"o": {"user_id": "@bob.uid"}}}`)}},
+			`x.json: o: reference @bob.uid: no field "uid"`},
+		{"Duplicate", fstest.MapFS{
+			"a.json": {Data: []byte(`{"users": {"bob": {}}}`)},
+			"b.json": {Data: []byte(`{"users": {"bob": {}}}`)},
+		}, `b.json: duplicate fixture id "bob", also in a.json`},
+		{"Cycle", fstest.MapFS{"x.json": {Data: []byte(`{"orders": {"a": {"tags": ["@b"]}, "b": {"tags": ["@a"]}}}`)}},
+			"fixture reference cycle: a -> b -> a"},
+		{"NotAnObject", fstest.MapFS{"x.json": {Data: []byte(`[1]`)}}, "x.json: expected an object of kinds"},
+		{"Syntax", fstest.MapFS{"x.json": {Data: []byte(`{`)}}, "x.json: unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inserted []string
+			_, err := newLoader(&inserted).Load(context.Background(), tt.files)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("Sink", func(t *testing.T) {
+		l := NewLoader()
+		l.Register("users", NewKind(func(context.Context, *user) error { return errors.New("duplicate key") }))
+		_, err := l.Load(context.Background(), fstest.MapFS{"x.json": {Data: []byte(`{"users": {"bob": {}}}`)}})
+		if err == nil || err.Error() != "x.json: bob: duplicate key" {
+			t.Errorf("expected sink error with context, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ErrCleanupTimeout is reported for cleanups that did not return before their deadline.
+var ErrCleanupTimeout = errors.New("cleanup timed out")
+
+// ErrCleanupSkipped is reported for cleanups not started because the cleanup budget
+// was already spent. Starting them would leave them running past Cleanup.
+var ErrCleanupSkipped = errors.New("skipped: cleanup budget exhausted")
+
+// errCleanupExited is reported for cleanups that called runtime.Goexit, e.g. via t.FailNow.
+var errCleanupExited = errors.New("cleanup exited without returning")
+
+// anonymousCleanup labels cleanups registered without a resource name.
+const anonymousCleanup = "<cleanup>"
+
+// cleanupEntry is a single cleanup attached to a node.
+type cleanupEntry struct {
+	name    string
+	fn      func(context.Context) error
+	timeout time.Duration
+}
+
+// CleanupResult describes the outcome of a single cleanup.
+type CleanupResult struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+	Panic    any
+	Stack    []byte
+}
+
+// Failed reports whether the cleanup returned an error, panicked or timed out.
+func (r CleanupResult) Failed() bool {
+	return r.Err != nil || r.Panic != nil
+}
+
+// contextCleanup adapts a plain cleanup to the context-aware signature.
+func contextCleanup(cleanup func() error) func(context.Context) error {
+	if cleanup == nil {
+		return nil
+	}
+	return func(context.Context) error { return cleanup() }
+}
+
+// runCleanups runs ops in order, and the entries of each op newest first.
+// budget bounds the whole run; zero means no global deadline. Once it is spent,
+// the remaining cleanups are skipped rather than started.
+func runCleanups(ops [][]cleanupEntry, budget time.Duration) []CleanupResult {
+	ctx := context.Background()
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	var results []CleanupResult
+	for _, entries := range ops {
+		// A resource registered several times tears down its newest value first
+		for i := len(entries) - 1; i >= 0; i-- {
+			if ctx.Err() != nil {
+				results = append(results, CleanupResult{Name: entries[i].name, Err: ErrCleanupSkipped})
+				continue
+			}
+			results = append(results, entries[i].run(ctx))
+		}
+	}
+	return results
+}
+
+// run executes the cleanup on its own goroutine so that a hung cleanup cannot block
+// past its deadline and a panicking cleanup cannot skip the remaining ones.
+func (e cleanupEntry) run(parent context.Context) CleanupResult {
+	ctx := parent
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, e.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan CleanupResult, 1)
+	go func() {
+		returned := false
+		defer func() {
+			if r := recover(); r != nil {
+				done <- CleanupResult{Panic: r, Stack: debug.Stack()}
+			} else if !returned {
+				done <- CleanupResult{Err: errCleanupExited}
+			}
+		}()
+		err := e.fn(ctx)
+		returned = true
+		done <- CleanupResult{Err: err}
+	}()
+
+	var res CleanupResult
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		// Prefer a result that raced with the deadline over reporting a timeout
+		select {
+		case res = <-done:
+		default:
+			res = CleanupResult{Err: fmt.Errorf("%w: %w", ErrCleanupTimeout, context.Cause(ctx))}
+		}
+	}
+	res.Name = e.name
+	res.Duration = time.Since(start)
+	return res
+}
+
+// FormatCleanupReport renders results as a single table, or "" if none failed.
+func FormatCleanupReport(results []CleanupResult) string {
+	failed := 0
+	for _, r := range results {
+		if r.Failed() {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "cleanup failed for %d of %d resource(s):\n", failed, len(results))
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, r := range results {
+		status := "ok"
+		switch {
+		case r.Panic != nil:
+			status = fmt.Sprintf("panic: %v", r.Panic)
+		case errors.Is(r.Err, ErrCleanupSkipped):
+			status = r.Err.Error()
+		case r.Err != nil:
+			status = fmt.Sprintf("error: %v", r.Err)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", r.Name, r.Duration.Round(time.Microsecond), status)
+	}
+	_ = w.Flush()
+	for _, r := range results {
+		if r.Panic != nil {
+			fmt.Fprintf(&b, "\npanic in cleanup %s:\n%s", r.Name, r.Stack)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// joinCleanupErrors converts failed results into a single error.
+func joinCleanupErrors(results []CleanupResult) error {
+	var errs []error
+	for _, r := range results {
+		switch {
+		case r.Panic != nil:
+			errs = append(errs, fmt.Errorf("cleanup %s panicked: %v", r.Name, r.Panic))
+		case r.Err != nil:
+			errs = append(errs, fmt.Errorf("cleanup %s failed: %w", r.Name, r.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
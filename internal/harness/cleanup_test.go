@@ -0,0 +1,185 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type reportTB struct {
+	mockTB
+	reports []string
+}
+
+func (m *reportTB) Errorf(format string, args ...any) {
+	m.reports = append(m.reports, fmt.Sprintf(format, args...))
+}
+
+func TestHarness_CleanupIsolation(t *testing.T) {
+	t.Run("PanicDoesNotSkipRemaining", func(t *testing.T) {
+		mtb := &reportTB{}
+		h := New(mtb)
+		var order []string
+		_ = h.AddResource("db", nil, func() error {
+			order = append(order, "db")
+			return nil
+		})
+		_ = h.AddResource("cache", nil, func() error {
+			panic("nil map")
+		})
+		h.RegisterCleanup(func() { order = append(order, "custom") })
+		h.Cleanup()
+
+		if !slices.Equal(order, []string{"custom", "db"}) {
+			t.Errorf("expected remaining cleanups to run, got %v", order)
+		}
+		if len(mtb.reports) != 1 {
+			t.Fatalf("expected a single aggregated report, got %d", len(mtb.reports))
+		}
+		for _, want := range []string{"cleanup failed for 1 of 3 resource(s)", "cache", "panic: nil map", "<cleanup>", "db"} {
+			if !strings.Contains(mtb.reports[0], want) {
+				t.Errorf("expected report to contain %q:\n%s", want, mtb.reports[0])
+			}
+		}
+	})
+
+	t.Run("ResourceTimeout", func(t *testing.T) {
+		mtb := &reportTB{}
+		h := New(mtb)
+		hung := make(chan struct{})
+		defer close(hung)
+		_ = h.AddResource("hung", nil, func() error {
+			<-hung
+			return nil
+		}, CleanupTimeout(10*time.Millisecond))
+		cancelled := make(chan struct{})
+		_ = h.AddResourceContext("ctx", nil, func(ctx context.Context) error {
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		}, CleanupTimeout(10*time.Millisecond))
+
+		done := make(chan struct{})
+		go func() {
+			h.Cleanup()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected Cleanup to return despite a hung cleanup")
+		}
+
+		select {
+		case <-cancelled:
+		case <-time.After(5 * time.Second):
+			t.Error("expected context-aware cleanup to observe cancellation")
+		}
+		if len(mtb.reports) != 1 || !strings.Contains(mtb.reports[0], "hung") ||
+			!strings.Contains(mtb.reports[0], ErrCleanupTimeout.Error()) {
+			t.Errorf("expected timeout in report, got %v", mtb.reports)
+		}
+	})
+
+	t.Run("GlobalTimeout", func(t *testing.T) {
+		mtb := &reportTB{}
+		h := New(mtb)
+		h.SetCleanupTimeout(10 * time.Millisecond)
+		hung := make(chan struct{})
+		defer close(hung)
+		_ = h.AddResource("first", nil, func() error {
+			<-hung
+			return nil
+		})
+		_ = h.AddResource("second", nil, func() error {
+			<-hung
+			return nil
+		})
+		h.Cleanup()
+
+		if len(mtb.reports) != 1 || !strings.Contains(mtb.reports[0], "cleanup failed for 2 of 2 resource(s)") {
+			t.Errorf("expected both cleanups to hit the global deadline, got %v", mtb.reports)
+		}
+	})
+
+	t.Run("BudgetExhaustedSkipsRemaining", func(t *testing.T) {
+		mtb := &reportTB{}
+		h := New(mtb)
+		h.SetCleanupTimeout(10 * time.Millisecond)
+		release := make(chan struct{})
+		var mu sync.Mutex
+		var ran []string
+		record := func(name string) func() error {
+			return func() error {
+				mu.Lock()
+				defer mu.Unlock()
+				ran = append(ran, name)
+				return nil
+			}
+		}
+		_ = h.AddResource("quick2", nil, record("quick2"))
+		_ = h.AddResource("quick1", nil, record("quick1"))
+		_ = h.AddResource("slow", nil, func() error {
+			<-release
+			return nil
+		})
+		h.Cleanup()
+		close(release)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(ran) != 0 {
+			t.Errorf("expected cleanups after the budget not to start, got %v", ran)
+		}
+		if len(mtb.reports) != 1 || !strings.Contains(mtb.reports[0], "slow") ||
+			!strings.Contains(mtb.reports[0], ErrCleanupTimeout.Error()) ||
+			strings.Count(mtb.reports[0], "skipped: cleanup budget exhausted") != 2 ||
+			strings.Contains(mtb.reports[0], "error: skipped") {
+			t.Errorf("expected slow to time out and the rest to be skipped, got %v", mtb.reports)
+		}
+	})
+}
+
+func TestFormatCleanupReport(t *testing.T) {
+	if report := FormatCleanupReport([]CleanupResult{{Name: "db"}}); report != "" {
+		t.Errorf("expected empty report without failures, got %q", report)
+	}
+
+	report := FormatCleanupReport([]CleanupResult{
+		{Name: "db", Duration: time.Millisecond, Err: errors.New("boom")},
+		{Name: "cache", Duration: 2 * time.Millisecond},
+	})
+	want := "cleanup failed for 1 of 2 resource(s):\n" +
+		"  db     1ms  error: boom\n" +
+		"  cache  2ms  ok"
+	if report != want {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s", report, want)
+	}
+}
+
+func TestHarness_Finalizers(t *testing.T) {
+	mtb := &reportTB{}
+	h := New(mtb)
+	var order []string
+	_ = h.AddResource("db", nil, func() error {
+		order = append(order, "cleanup")
+		return nil
+	})
+	h.AddFinalizer(func() { order = append(order, "finalizer 1") })
+	h.AddCheck(func() error {
+		order = append(order, "check")
+		return errors.New("leak")
+	})
+	h.AddFinalizer(func() { order = append(order, fmt.Sprintf("finalizer 2 after %d report(s)", len(mtb.reports))) })
+	h.Cleanup()
+
+	want := []string{"cleanup", "check", "finalizer 1", "finalizer 2 after 1 report(s)"}
+	if !slices.Equal(order, want) {
+		t.Errorf("expected finalizers to run last, got %v", order)
+	}
+}
@@ -0,0 +1,180 @@
+package harness
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMissingDependency is returned when a resource depends on a name that is not registered.
+	ErrMissingDependency = errors.New("missing dependency")
+	// ErrDependencyCycle is returned when registering a resource would create a dependency cycle.
+	ErrDependencyCycle = errors.New("dependency cycle")
+)
+
+// ResourceOption configures how a resource is registered.
+type ResourceOption func(*resourceConfig)
+
+type resourceConfig struct {
+	deps           []string
+	cleanupTimeout time.Duration
+	ready          *readiness
+	eager          bool
+}
+
+func newResourceConfig(opts []ResourceOption) resourceConfig {
+	var cfg resourceConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// DependsOn declares that the resource depends on the named resources.
+// Dependencies are set up before and torn down after their dependents.
+func DependsOn(names ...string) ResourceOption {
+	return func(c *resourceConfig) {
+		for _, name := range names {
+			if !slices.Contains(c.deps, name) {
+				c.deps = append(c.deps, name)
+			}
+		}
+	}
+}
+
+// CleanupTimeout bounds how long the resource's cleanup may run. The cleanup's
+// context is cancelled at the deadline and the harness moves on to the next cleanup.
+func CleanupTimeout(d time.Duration) ResourceOption {
+	return func(c *resourceConfig) {
+		c.cleanupTimeout = d
+	}
+}
+
+// Eager makes a provider-backed resource construct during Start instead of on first
+// access. Eager providers run concurrently with those they do not depend on.
+func Eager() ResourceOption {
+	return func(c *resourceConfig) {
+		c.eager = true
+	}
+}
+
+// node is a vertex of the resource graph. Anonymous nodes hold cleanups registered
+// with RegisterCleanup and never participate in dependency edges.
+type node struct {
+	name     string
+	value    any
+	deps     []string
+	cleanups []cleanupEntry
+	seq      int
+	lazy     *lazyValue
+
+	ready        *readiness
+	readyChecked bool
+}
+
+// validateDeps checks that every dependency exists in nodes (or, if inherited is
+// non-nil, outside of them) and that giving name the provided dependencies keeps
+// the graph acyclic. Inherited resources always outlive local ones, so they never
+// take part in cycles.
+func validateDeps(nodes map[string]*node, name string, deps []string, inherited func(string) bool) error {
+	for _, dep := range deps {
+		if dep == name {
+			return fmt.Errorf("%w: %s -> %s", ErrDependencyCycle, name, name)
+		}
+		if _, ok := nodes[dep]; !ok && (inherited == nil || !inherited(dep)) {
+			return fmt.Errorf("%w: %s depends on unregistered resource %q", ErrMissingDependency, name, dep)
+		}
+	}
+
+	edges := func(n string) []string {
+		if n == name {
+			return deps
+		}
+		if existing, ok := nodes[n]; ok {
+			return existing.deps
+		}
+		return nil
+	}
+
+	// Depth-first search from each dependency looking for a path back to name.
+	visited := make(map[string]bool)
+	var walk func(n string, path []string) []string
+	walk = func(n string, path []string) []string {
+		path = append(path, n)
+		if n == name {
+			return path
+		}
+		if visited[n] {
+			return nil
+		}
+		visited[n] = true
+		for _, next := range edges(n) {
+			if cycle := walk(next, path); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	for _, dep := range deps {
+		if cycle := walk(dep, []string{name}); cycle != nil {
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+		}
+	}
+	return nil
+}
+
+// teardownOrder returns the cleanups of all nodes in reverse topological order.
+// Among nodes whose dependents have all been torn down, the most recently
+// registered one goes first, which preserves plain LIFO for independent cleanups.
+func teardownOrder(nodes []*node) [][]cleanupEntry {
+	sorted := slices.Clone(nodes)
+	slices.SortStableFunc(sorted, func(a, b *node) int { return a.seq - b.seq })
+
+	byName := make(map[string]*node, len(sorted))
+	for _, n := range sorted {
+		if n.name != "" {
+			byName[n.name] = n
+		}
+	}
+	dependents := make(map[*node]int, len(sorted))
+	for _, n := range sorted {
+		for _, dep := range n.deps {
+			if d, ok := byName[dep]; ok {
+				dependents[d]++
+			}
+		}
+	}
+
+	done := make([]bool, len(sorted))
+	ops := make([][]cleanupEntry, 0, len(sorted))
+	for len(ops) < len(sorted) {
+		next := -1
+		for i := len(sorted) - 1; i >= 0; i-- {
+			if !done[i] && dependents[sorted[i]] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			// Unreachable while registration rejects cycles; fall back to LIFO.
+			for i := len(sorted) - 1; i >= 0; i-- {
+				if !done[i] {
+					next = i
+					break
+				}
+			}
+		}
+		done[next] = true
+		n := sorted[next]
+		for _, dep := range n.deps {
+			if d, ok := byName[dep]; ok {
+				dependents[d]--
+			}
+		}
+		ops = append(ops, slices.Clone(n.cleanups))
+	}
+	return ops
+}
@@ -0,0 +1,96 @@
+package harness
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestHarness_DependencyValidation(t *testing.T) {
+	t.Run("MissingDependency", func(t *testing.T) {
+		h := New(t)
+		err := h.AddResource("pool", "p", nil, DependsOn("db"))
+		if !errors.Is(err, ErrMissingDependency) {
+			t.Fatalf("expected ErrMissingDependency, got %v", err)
+		}
+		if _, ok := h.Resource("pool"); ok {
+			t.Error("expected rejected resource not to be registered")
+		}
+	})
+
+	t.Run("SelfDependency", func(t *testing.T) {
+		h := New(t)
+		err := h.AddResource("db", "d", nil, DependsOn("db"))
+		if !errors.Is(err, ErrDependencyCycle) {
+			t.Fatalf("expected ErrDependencyCycle, got %v", err)
+		}
+	})
+
+	t.Run("CycleThroughReplacement", func(t *testing.T) {
+		h := New(t)
+		if err := h.AddResource("a", 1, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := h.AddResource("b", 2, nil, DependsOn("a")); err != nil {
+			t.Fatal(err)
+		}
+		err := h.AddResource("a", 3, nil, DependsOn("b"))
+		if !errors.Is(err, ErrDependencyCycle) {
+			t.Fatalf("expected ErrDependencyCycle, got %v", err)
+		}
+		if err.Error() != "dependency cycle: a -> b -> a" {
+			t.Errorf("unexpected cycle message: %v", err)
+		}
+		if val, _ := h.Resource("a"); val != 1 {
+			t.Errorf("expected original value to be kept, got %v", val)
+		}
+	})
+}
+
+func TestHarness_TeardownOrder(t *testing.T) {
+	t.Run("DependentsFirst", func(t *testing.T) {
+		var order []string
+		record := func(name string) func() error {
+			return func() error {
+				order = append(order, name)
+				return nil
+			}
+		}
+
+		h := New(t)
+		_ = h.AddResource("db", nil, record("db"))
+		_ = h.AddResource("cache", nil, record("cache"))
+		_ = h.AddResource("migrations", nil, record("migrations"), DependsOn("db"))
+		// Re-registering db makes it the most recent entry; plain LIFO would tear it down first.
+		_ = h.AddResource("db", nil, record("db2"))
+		h.RegisterCleanup(func() { order = append(order, "custom") })
+		h.Cleanup()
+
+		want := []string{"custom", "migrations", "db2", "db", "cache"}
+		if !slices.Equal(order, want) {
+			t.Errorf("expected %v, got %v", want, order)
+		}
+	})
+
+	t.Run("Diamond", func(t *testing.T) {
+		var order []string
+		record := func(name string) func() error {
+			return func() error {
+				order = append(order, name)
+				return nil
+			}
+		}
+
+		h := New(t)
+		_ = h.AddResource("config", nil, record("config"))
+		_ = h.AddResource("db", nil, record("db"), DependsOn("config"))
+		_ = h.AddResource("queue", nil, record("queue"), DependsOn("config"))
+		_ = h.AddResource("service", nil, record("service"), DependsOn("db", "queue"))
+		h.Cleanup()
+
+		want := []string{"service", "queue", "db", "config"}
+		if !slices.Equal(order, want) {
+			t.Errorf("expected %v, got %v", want, order)
+		}
+	})
+}
@@ -2,26 +2,51 @@
 package harness
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"sync"
 	"testing"
+	"time"
 )
 
+// ErrHarnessClosed is the cause of Context cancellation once the harness cleans up.
+var ErrHarnessClosed = errors.New("harness closed")
+
 // Harness is a generic container for test resources.
 type Harness struct {
-	t testing.TB
+	t      testing.TB
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	mu         sync.RWMutex
+	nodes      map[string]*node
+	order      []*node
+	seq        int
+	cleanOnce  sync.Once
+	budget     time.Duration
+	checks     []func() error
+	finalizers []func()
 
-	mu        sync.RWMutex
-	resources map[string]any
-	cleanups  []func()
-	cleanOnce sync.Once
+	parent   *Harness
+	suite    *Suite
+	borrowed map[string]bool
 }
 
 // New creates a new Harness instance.
 func New(t testing.TB) *Harness {
+	return newHarness(t, context.Background())
+}
+
+func newHarness(t testing.TB, parent context.Context) *Harness {
+	ctx, cancel := context.WithCancelCause(parent)
 	h := &Harness{
-		t:         t,
-		resources: make(map[string]any),
-		cleanups:  make([]func(), 0),
+		t:      t,
+		ctx:    ctx,
+		cancel: cancel,
+		nodes:  make(map[string]*node),
+		order:  make([]*node, 0),
 	}
 	// Automatically register Cleanup to run at the end of the test
 	t.Cleanup(h.Cleanup)
@@ -33,30 +58,197 @@ func (h *Harness) T() testing.TB {
 	return h.t
 }
 
+// Context returns a context that is cancelled when the harness starts cleaning up,
+// or when Start fails. Providers and long-running helpers should honour it.
+func (h *Harness) Context() context.Context {
+	return h.ctx
+}
+
 // SetResource adds a named resource to the harness and registers its cleanup if provided.
-func (h *Harness) SetResource(name string, value any, cleanup func() error) {
+// Registration errors (missing dependencies, cycles) fail the test immediately.
+func (h *Harness) SetResource(name string, value any, cleanup func() error, opts ...ResourceOption) {
+	if err := h.AddResource(name, value, cleanup, opts...); err != nil {
+		h.t.Fatalf("resource %s: %v", name, err)
+	}
+}
+
+// AddResource adds a named resource to the harness and registers its cleanup if provided.
+// Dependencies declared with DependsOn must already be registered, and the resulting
+// graph must stay acyclic; otherwise the resource is not registered and an error is returned.
+func (h *Harness) AddResource(name string, value any, cleanup func() error, opts ...ResourceOption) error {
+	return h.AddResourceContext(name, value, contextCleanup(cleanup), opts...)
+}
+
+// AddResourceContext is like AddResource but takes a context-aware cleanup. The context
+// is cancelled when the resource's CleanupTimeout or the harness cleanup timeout expires.
+func (h *Harness) AddResourceContext(name string, value any, cleanup func(context.Context) error, opts ...ResourceOption) error {
+	cfg := newResourceConfig(opts)
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	h.resources[name] = value
+	if err := validateDeps(h.nodes, name, cfg.deps, h.inherits); err != nil {
+		return err
+	}
 
-	if cleanup != nil {
-		// Wrap cleanup to handle error logging
-		wrapped := func() {
-			if err := cleanup(); err != nil {
-				h.t.Errorf("cleanup %s failed: %v", name, err)
-			}
-		}
-		h.cleanups = append(h.cleanups, wrapped)
+	n, ok := h.nodes[name]
+	if !ok {
+		n = &node{name: name}
+		h.nodes[name] = n
+		h.order = append(h.order, n)
 	}
+	h.seq++
+	n.seq = h.seq
+	n.value = value
+	n.deps = cfg.deps
+	n.lazy = nil
+	n.ready = cfg.ready
+	n.readyChecked = false
+	addCleanup(n, name, cleanup, cfg.cleanupTimeout)
+	return nil
+}
+
+// addCleanup attaches cleanup to n, labelled with name in the cleanup report.
+// The caller must hold the lock guarding n.
+func addCleanup(n *node, name string, cleanup func(context.Context) error, timeout time.Duration) {
+	if cleanup == nil {
+		return
+	}
+	n.cleanups = append(n.cleanups, cleanupEntry{name: name, fn: cleanup, timeout: timeout})
+}
+
+// SetCleanupTimeout bounds the total time Cleanup may spend on all cleanups.
+// Zero, the default, means no global deadline. Cleanups not yet started when it
+// runs out are skipped and reported.
+func (h *Harness) SetCleanupTimeout(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.budget = d
 }
 
 // Resource retrieves a named resource from the harness.
+// Resources registered with AddProvider are constructed on first access; construction
+// failures fail the test. Names not registered locally are resolved through the parent
+// chain and then the suite.
 func (h *Harness) Resource(name string) (any, bool) {
+	h.t.Helper()
+	val, ok, err := h.lookup(name)
+	if err != nil {
+		h.t.Fatalf("%v", err)
+		return nil, false
+	}
+	return val, ok
+}
+
+// lookup resolves name locally, then in the parent chain, then in the suite,
+// constructing provider-backed resources on the way.
+func (h *Harness) lookup(name string) (any, bool, error) {
+	h.mu.RLock()
+	n, ok := h.nodes[name]
+	var lazy *lazyValue
+	if ok {
+		lazy = n.lazy
+	}
+	parent := h.parent
+	h.mu.RUnlock()
+
+	if ok {
+		if lazy != nil {
+			val, err := h.construct(n, lazy)
+			if err != nil {
+				return nil, false, fmt.Errorf("resource %s: %w", name, err)
+			}
+			return val, true, nil
+		}
+		h.mu.RLock()
+		defer h.mu.RUnlock()
+		return n.value, true, nil
+	}
+	if parent != nil {
+		if val, ok, err := parent.lookup(name); ok || err != nil {
+			return val, ok, err
+		}
+	}
+	val, ok := h.borrow(name)
+	return val, ok, nil
+}
+
+// Values returns the resources registered on h itself, keyed by name. Providers that
+// have not been constructed, and resources of the parent chain or suite, are excluded.
+func (h *Harness) Values() map[string]any {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	values := make(map[string]any, len(h.nodes))
+	for name, n := range h.nodes {
+		if n.lazy != nil && !n.lazy.built {
+			continue
+		}
+		values[name] = n.value
+	}
+	return values
+}
+
+// has reports whether name resolves through h without constructing or borrowing it.
+func (h *Harness) has(name string) bool {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
+	_, ok := h.nodes[name]
+	return ok || h.inherits(name)
+}
+
+// inherits reports whether name resolves outside of h's own resources.
+// The caller must hold h.mu.
+func (h *Harness) inherits(name string) bool {
+	if h.parent != nil && h.parent.has(name) {
+		return true
+	}
+	return h.suite != nil && h.suite.has(name)
+}
+
+// Sub returns a child harness bound to t, typically the testing.TB of a t.Run subtest.
+// The child resolves resources through h, may shadow them with local registrations,
+// and runs only its own cleanups when t finishes.
+func (h *Harness) Sub(t testing.TB) *Harness {
+	child := newHarness(t, h.ctx)
+	child.parent = h
+	return child
+}
+
+// UseSuite makes the shared resources of s available through Resource.
+// Local resources with the same name shadow shared ones. Borrowed resources are
+// released when the harness is cleaned up; the suite keeps ownership of teardown.
+func (h *Harness) UseSuite(s *Suite) {
+	h.mu.Lock()
+	h.suite = s
+	h.borrowed = make(map[string]bool)
+	h.mu.Unlock()
+
+	h.RegisterCleanup(func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for name := range h.borrowed {
+			s.release(name)
+		}
+		h.borrowed = make(map[string]bool)
+	})
+}
+
+// borrow looks name up in the suite, taking at most one reference per harness.
+func (h *Harness) borrow(name string) (any, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	val, ok := h.resources[name]
+	if h.suite == nil {
+		return nil, false
+	}
+	if h.borrowed[name] {
+		return h.suite.peek(name)
+	}
+	val, ok := h.suite.acquire(name)
+	if ok {
+		h.borrowed[name] = true
+	}
 	return val, ok
 }
 
@@ -65,22 +257,66 @@ func (h *Harness) Resource(name string) (any, bool) {
 func (h *Harness) RegisterCleanup(fn func()) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	h.cleanups = append(h.cleanups, fn)
+	h.seq++
+	h.order = append(h.order, &node{seq: h.seq, cleanups: []cleanupEntry{{
+		name: anonymousCleanup,
+		fn: func(context.Context) error {
+			fn()
+			return nil
+		},
+	}}})
 }
 
-// Cleanup runs all registered cleanups in LIFO order.
+// AddCheck registers a check that runs after all cleanups have completed, such as a
+// leak detector. Checks run in registration order and each error fails the test.
+func (h *Harness) AddCheck(check func() error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, check)
+}
+
+// AddFinalizer registers fn to run after all cleanups and checks, when the outcome of
+// the test is final, e.g. to log diagnostics if t.Failed(). Finalizers run in
+// registration order.
+func (h *Harness) AddFinalizer(fn func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.finalizers = append(h.finalizers, fn)
+}
+
+// Cleanup runs all registered cleanups in reverse topological order: a resource is
+// torn down only after every resource depending on it. Independent cleanups run in
+// LIFO order. A panicking or timed-out cleanup does not prevent the remaining ones
+// from running; failures are reported to the test as a single aggregated report.
 // It is idempotent and safe to call multiple times.
 func (h *Harness) Cleanup() {
 	h.cleanOnce.Do(func() {
+		h.cancel(ErrHarnessClosed)
+
 		h.mu.Lock()
-		// Copy cleanups to allow releasing the lock while running them
-		ops := make([]func(), len(h.cleanups))
-		copy(ops, h.cleanups)
+		// Compute the order while holding the lock, then release it while running cleanups
+		ops := teardownOrder(h.order)
+		budget := h.budget
 		h.mu.Unlock()
 
-		// Run cleanups in reverse order
-		for i := len(ops) - 1; i >= 0; i-- {
-			ops[i]()
+		if report := FormatCleanupReport(runCleanups(ops, budget)); report != "" {
+			h.t.Errorf("%s", report)
+		}
+
+		h.mu.RLock()
+		checks := slices.Clone(h.checks)
+		h.mu.RUnlock()
+		for _, check := range checks {
+			if err := check(); err != nil {
+				h.t.Errorf("%v", err)
+			}
+		}
+
+		h.mu.RLock()
+		finalizers := slices.Clone(h.finalizers)
+		h.mu.RUnlock()
+		for _, fn := range finalizers {
+			fn()
 		}
 	})
 }
@@ -15,6 +15,8 @@ func (m *mockTB) Cleanup(f func()) {
 	m.cleanupFuncs = append(m.cleanupFuncs, f)
 }
 
+func (m *mockTB) Helper() {}
+
 func (m *mockTB) Errorf(format string, _ ...any) {
 	m.errors = append(m.errors, format)
 }
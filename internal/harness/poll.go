@@ -0,0 +1,133 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/next-trace/scg-test-kit/internal/probe"
+)
+
+const (
+	// DefaultEventuallyTimeout bounds Eventually when no PollTimeout is given.
+	DefaultEventuallyTimeout = 5 * time.Second
+	// DefaultConsistentlyDuration is how long Consistently polls when no PollTimeout is given.
+	DefaultConsistentlyDuration = 500 * time.Millisecond
+)
+
+// DefaultPollBackoff is the delay between attempts when no PollInterval or PollBackoff is given.
+var DefaultPollBackoff = probe.Backoff{Initial: 10 * time.Millisecond, Max: 200 * time.Millisecond}
+
+// deadlineMargin is kept free before the test deadline so a failing poll is
+// reported, and the harness cleaned up, before the test binary panics.
+const deadlineMargin = time.Second
+
+// PollOption configures Eventually and Consistently.
+type PollOption func(*pollConfig)
+
+type pollConfig struct {
+	backoff probe.Backoff
+	timeout time.Duration
+}
+
+// PollInterval polls at a fixed interval.
+func PollInterval(d time.Duration) PollOption {
+	return func(c *pollConfig) {
+		c.backoff = probe.Backoff{Initial: d, Max: d}
+	}
+}
+
+// PollBackoff waits initial after the first attempt and doubles the delay up to maxDelay.
+func PollBackoff(initial, maxDelay time.Duration) PollOption {
+	return func(c *pollConfig) {
+		c.backoff = probe.Backoff{Initial: initial, Max: maxDelay}
+	}
+}
+
+// PollTimeout sets how long Eventually waits for the condition to hold, or how long
+// Consistently checks that it keeps holding.
+func PollTimeout(d time.Duration) PollOption {
+	return func(c *pollConfig) {
+		c.timeout = d
+	}
+}
+
+func (h *Harness) pollConfig(opts []PollOption, timeout time.Duration) pollConfig {
+	cfg := pollConfig{backoff: DefaultPollBackoff, timeout: timeout}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.backoff.Initial <= 0 {
+		cfg.backoff = DefaultPollBackoff
+	}
+	if cfg.backoff.Max < cfg.backoff.Initial {
+		cfg.backoff.Max = cfg.backoff.Initial
+	}
+	if cfg.timeout <= 0 {
+		cfg.timeout = timeout
+	}
+	// Only *testing.T has a deadline; benchmarks and fuzz targets do not
+	if d, ok := h.t.(interface{ Deadline() (time.Time, bool) }); ok {
+		if deadline, ok := d.Deadline(); ok {
+			remaining := time.Until(deadline)
+			cfg.timeout = min(cfg.timeout, max(remaining-deadlineMargin, remaining/2))
+		}
+	}
+	return cfg
+}
+
+// Eventually calls condition until it returns nil and reports whether it did. If the
+// condition still fails when the timeout or the test deadline approaches, the last
+// error is reported to the test. Polling stops silently when the harness cleans up.
+// The condition's context is cancelled at the timeout.
+func (h *Harness) Eventually(condition func(ctx context.Context) error, opts ...PollOption) bool {
+	h.t.Helper()
+	cfg := h.pollConfig(opts, DefaultEventuallyTimeout)
+	err := probe.Wait(h.ctx, condition, cfg.backoff, cfg.timeout)
+	if err == nil {
+		return true
+	}
+	if h.ctx.Err() != nil {
+		return false
+	}
+	var notReady *probe.NotReadyError
+	if errors.As(err, &notReady) {
+		err = fmt.Errorf("condition not met after %s (%d attempts): %w", notReady.Elapsed.Round(time.Millisecond), notReady.Attempts, notReady.Last)
+	}
+	h.t.Errorf("eventually: %v", err)
+	return false
+}
+
+// Consistently calls condition until the timeout and reports whether it returned nil
+// every time. The first error is reported to the test and stops polling. Polling
+// stops early, reporting false, when the harness cleans up.
+func (h *Harness) Consistently(condition func(ctx context.Context) error, opts ...PollOption) bool {
+	h.t.Helper()
+	cfg := h.pollConfig(opts, DefaultConsistentlyDuration)
+	ctx, cancel := context.WithTimeout(h.ctx, cfg.timeout)
+	defer cancel()
+
+	start := time.Now()
+	delay := cfg.backoff.Initial
+	for attempt := 1; ; attempt++ {
+		err := condition(ctx)
+		if ctx.Err() != nil {
+			// The period ended while the condition ran, so its result is not meaningful
+			return h.ctx.Err() == nil
+		}
+		if err != nil {
+			h.t.Errorf("consistently: condition failed after %s (attempt %d): %v", time.Since(start).Round(time.Millisecond), attempt, err)
+			return false
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return h.ctx.Err() == nil
+		case <-timer.C:
+		}
+		delay = min(delay*2, cfg.backoff.Max)
+	}
+}
@@ -0,0 +1,164 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type deadlineTB struct {
+	reportTB
+	deadline time.Time
+}
+
+func (m *deadlineTB) Deadline() (time.Time, bool) {
+	return m.deadline, true
+}
+
+func TestHarness_Eventually(t *testing.T) {
+	fast := PollInterval(time.Millisecond)
+
+	t.Run("Succeeds", func(t *testing.T) {
+		h := New(t)
+		attempts := 0
+		ok := h.Eventually(func(context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		}, fast)
+		if !ok || attempts != 3 {
+			t.Errorf("expected success on third attempt, got ok=%v attempts=%d", ok, attempts)
+		}
+	})
+
+	t.Run("ReportsLastError", func(t *testing.T) {
+		mtb := &reportTB{mockTB: mockTB{TB: t}}
+		h := New(mtb)
+		attempts := 0
+		ok := h.Eventually(func(context.Context) error {
+			attempts++
+			if attempts == 1 {
+				return errors.New("first")
+			}
+			return errors.New("still pending")
+		}, fast, PollTimeout(20*time.Millisecond))
+
+		if ok || len(mtb.reports) != 1 {
+			t.Fatalf("expected one failure report, got ok=%v reports=%v", ok, mtb.reports)
+		}
+		if report := mtb.reports[0]; !strings.Contains(report, "condition not met after") ||
+			!strings.Contains(report, "still pending") {
+			t.Errorf("expected last error in report, got %q", report)
+		}
+	})
+
+	t.Run("CappedByTestDeadline", func(t *testing.T) {
+		mtb := &deadlineTB{reportTB: reportTB{mockTB: mockTB{TB: t}}, deadline: time.Now().Add(100 * time.Millisecond)}
+		h := New(mtb)
+		start := time.Now()
+		ok := h.Eventually(func(context.Context) error { return errors.New("down") }, fast, PollTimeout(time.Minute))
+		if ok || len(mtb.reports) != 1 {
+			t.Fatalf("expected failure report, got ok=%v reports=%v", ok, mtb.reports)
+		}
+		if elapsed := time.Since(start); elapsed > 90*time.Millisecond {
+			t.Errorf("expected timeout to stop before the deadline, took %s", elapsed)
+		}
+	})
+
+	t.Run("CancelledByCleanup", func(t *testing.T) {
+		mtb := &reportTB{mockTB: mockTB{TB: t}}
+		h := New(mtb)
+		var attempts atomic.Int32
+		done := make(chan bool)
+		go func() {
+			done <- h.Eventually(func(context.Context) error {
+				attempts.Add(1)
+				return errors.New("down")
+			}, fast, PollTimeout(time.Minute))
+		}()
+		for attempts.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		for i := len(mtb.cleanupFuncs) - 1; i >= 0; i-- {
+			mtb.cleanupFuncs[i]()
+		}
+
+		select {
+		case ok := <-done:
+			if ok {
+				t.Error("expected Eventually to report false")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Eventually was not cancelled by cleanup")
+		}
+		if len(mtb.reports) != 0 {
+			t.Errorf("expected no failure report after cleanup, got %v", mtb.reports)
+		}
+	})
+}
+
+func TestHarness_Consistently(t *testing.T) {
+	fast := PollInterval(time.Millisecond)
+
+	t.Run("Holds", func(t *testing.T) {
+		h := New(t)
+		attempts := 0
+		ok := h.Consistently(func(context.Context) error {
+			attempts++
+			return nil
+		}, fast, PollTimeout(20*time.Millisecond))
+		if !ok || attempts < 2 {
+			t.Errorf("expected condition to be polled repeatedly, got ok=%v attempts=%d", ok, attempts)
+		}
+	})
+
+	t.Run("StopsAtFirstFailure", func(t *testing.T) {
+		mtb := &reportTB{mockTB: mockTB{TB: t}}
+		h := New(mtb)
+		attempts := 0
+		ok := h.Consistently(func(context.Context) error {
+			attempts++
+			if attempts == 3 {
+				return errors.New("drifted")
+			}
+			return nil
+		}, fast, PollTimeout(time.Second))
+
+		if ok || attempts != 3 || len(mtb.reports) != 1 {
+			t.Fatalf("expected failure on third attempt, got ok=%v attempts=%d reports=%v", ok, attempts, mtb.reports)
+		}
+		if !strings.Contains(mtb.reports[0], "attempt 3") || !strings.Contains(mtb.reports[0], "drifted") {
+			t.Errorf("unexpected report %q", mtb.reports[0])
+		}
+	})
+
+	t.Run("CancelledByCleanup", func(t *testing.T) {
+		mtb := &reportTB{mockTB: mockTB{TB: t}}
+		h := New(mtb)
+		done := make(chan bool)
+		go func() {
+			done <- h.Consistently(func(context.Context) error { return nil }, fast, PollTimeout(time.Minute))
+		}()
+		time.Sleep(5 * time.Millisecond)
+		for i := len(mtb.cleanupFuncs) - 1; i >= 0; i-- {
+			mtb.cleanupFuncs[i]()
+		}
+
+		select {
+		case ok := <-done:
+			if ok {
+				t.Error("expected Consistently to report false when cut short")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Consistently was not cancelled by cleanup")
+		}
+		if len(mtb.reports) != 0 {
+			t.Errorf("expected no failure report after cleanup, got %v", mtb.reports)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package harness
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// errConstructionExited is reported for providers that called runtime.Goexit, e.g.
+// via t.FailNow, so that later lookups do not mistake the resource for a nil value.
+var errConstructionExited = errors.New("provider construction did not complete")
+
+// Provider constructs a resource on demand. It returns the resource value,
+// an optional cleanup, and an error if construction failed.
+type Provider func(h *Harness) (any, func() error, error)
+
+// lazyValue tracks the construction state of a provider-backed resource.
+type lazyValue struct {
+	provider Provider
+	eager    bool
+	timeout  time.Duration
+	once     sync.Once
+	value    any
+	built    bool
+	err      error
+}
+
+// AddProvider registers a named resource that is constructed by provider the first
+// time it is requested. Its cleanup is registered only if it was actually constructed.
+// Dependencies follow the same rules as AddResource and are resolved before provider runs.
+func (h *Harness) AddProvider(name string, provider Provider, opts ...ResourceOption) error {
+	cfg := newResourceConfig(opts)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := validateDeps(h.nodes, name, cfg.deps, h.inherits); err != nil {
+		return err
+	}
+
+	n, ok := h.nodes[name]
+	if !ok {
+		n = &node{name: name}
+		h.nodes[name] = n
+		h.order = append(h.order, n)
+	}
+	h.seq++
+	n.seq = h.seq
+	n.value = nil
+	n.deps = cfg.deps
+	n.lazy = &lazyValue{provider: provider, eager: cfg.eager, timeout: cfg.cleanupTimeout}
+	n.ready = cfg.ready
+	n.readyChecked = false
+	return nil
+}
+
+// EnsureProvider registers provider under name like AddProvider, unless h itself
+// already has a resource of that name. Concurrent callers therefore share one
+// provider, and so one construction, without holding a lock while it runs.
+func (h *Harness) EnsureProvider(name string, provider Provider, opts ...ResourceOption) error {
+	cfg := newResourceConfig(opts)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, exists := h.nodes[name]; exists {
+		return nil
+	}
+	if err := validateDeps(h.nodes, name, cfg.deps, h.inherits); err != nil {
+		return err
+	}
+	n := &node{name: name, deps: cfg.deps, ready: cfg.ready}
+	n.lazy = &lazyValue{provider: provider, eager: cfg.eager, timeout: cfg.cleanupTimeout}
+	h.seq++
+	n.seq = h.seq
+	h.nodes[name] = n
+	h.order = append(h.order, n)
+	return nil
+}
+
+// construct runs the provider of n at most once and returns the cached result.
+func (h *Harness) construct(n *node, lazy *lazyValue) (any, error) {
+	lazy.once.Do(func() {
+		// Left in place if build never returns; sync.Once still counts it as done
+		lazy.err = errConstructionExited
+		lazy.err = h.build(n, lazy)
+	})
+	return lazy.value, lazy.err
+}
+
+// build resolves the dependencies of n, runs its provider and registers the cleanup.
+func (h *Harness) build(n *node, lazy *lazyValue) error {
+	h.mu.RLock()
+	deps := n.deps
+	h.mu.RUnlock()
+	for _, dep := range deps {
+		_, ok, err := h.lookup(dep)
+		if err != nil {
+			return fmt.Errorf("dependency unavailable: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("dependency %q unavailable", dep)
+		}
+	}
+
+	value, cleanup, err := lazy.provider(h)
+	if err != nil {
+		return fmt.Errorf("construction failed: %w", err)
+	}
+
+	if ready := h.attach(n, lazy, value, cleanup); ready != nil {
+		if err := ready.wait(h.ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attach stores the constructed value on n, registers its cleanup, and returns the
+// readiness probe still to be run, if any.
+func (h *Harness) attach(n *node, lazy *lazyValue, value any, cleanup func() error) *readiness {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	lazy.value = value
+	lazy.built = true
+	if n.lazy != lazy {
+		// Replaced while constructing: the value is unreachable but must still be torn down.
+		orphan := &node{seq: h.seq}
+		h.order = append(h.order, orphan)
+		addCleanup(orphan, n.name, contextCleanup(cleanup), lazy.timeout)
+		return nil
+	}
+	// Constructed resources are torn down in construction order rather than registration order
+	n.seq = h.seq
+	n.value = value
+	addCleanup(n, n.name, contextCleanup(cleanup), lazy.timeout)
+	n.readyChecked = true
+	return n.ready
+}
+
+// Start constructs all eager providers concurrently. Each provider waits only for the
+// providers it depends on, so independent ones start in parallel. The first failure
+// cancels Context so the remaining providers can abort; everything constructed so far
+// is then cleaned up and the failure is returned.
+func (h *Harness) Start() error {
+	h.mu.RLock()
+	var names []string
+	for _, n := range h.order {
+		if n.lazy != nil && n.lazy.eager {
+			names = append(names, n.name)
+		}
+	}
+	h.mu.RUnlock()
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	for _, name := range names {
+		wg.Go(func() {
+			if _, _, err := h.lookup(name); err != nil {
+				once.Do(func() {
+					first = err
+					h.cancel(fmt.Errorf("startup failed: %w", err))
+				})
+			}
+		})
+	}
+	wg.Wait()
+
+	if first != nil {
+		h.Cleanup()
+		return first
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fatalTB struct {
+	mockTB
+	fatals  []string
+	helpers int
+}
+
+func (m *fatalTB) Helper() { m.helpers++ }
+
+func (m *fatalTB) Fatalf(format string, args ...any) {
+	m.fatals = append(m.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestHarness_Provider(t *testing.T) {
+	t.Run("ConstructedOnce", func(t *testing.T) {
+		h := New(t)
+		calls := 0
+		_ = h.AddProvider("db", func(*Harness) (any, func() error, error) {
+			calls++
+			return "conn", nil, nil
+		})
+		if calls != 0 {
+			t.Fatal("expected provider not to run at registration")
+		}
+		for range 3 {
+			val, ok := h.Resource("db")
+			if !ok || val != "conn" {
+				t.Fatalf("expected conn, got %v (ok=%v)", val, ok)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("expected provider to run once, got %d", calls)
+		}
+	})
+
+	t.Run("CleanupOnlyIfConstructed", func(t *testing.T) {
+		var order []string
+		provider := func(name string) Provider {
+			return func(*Harness) (any, func() error, error) {
+				return name, func() error {
+					order = append(order, name)
+					return nil
+				}, nil
+			}
+		}
+
+		h := New(t)
+		_ = h.AddProvider("unused", provider("unused"))
+		_ = h.AddProvider("first", provider("first"))
+		_ = h.AddResource("eager", nil, func() error {
+			order = append(order, "eager")
+			return nil
+		})
+		_ = h.AddProvider("second", provider("second"), DependsOn("first"))
+		// Resolving second constructs first before it.
+		_, _ = h.Resource("second")
+		h.Cleanup()
+
+		want := []string{"second", "first", "eager"}
+		if !slices.Equal(order, want) {
+			t.Errorf("expected %v, got %v", want, order)
+		}
+	})
+
+	t.Run("ConstructionFailure", func(t *testing.T) {
+		mtb := &fatalTB{}
+		h := New(mtb)
+		_ = h.AddProvider("db", func(*Harness) (any, func() error, error) {
+			return nil, nil, errors.New("dial failed")
+		})
+		if _, ok := h.Resource("db"); ok {
+			t.Error("expected ok=false for failed provider")
+		}
+		if len(mtb.fatals) != 1 || !strings.Contains(mtb.fatals[0], "resource db: construction failed: dial failed") {
+			t.Errorf("unexpected failure messages: %v", mtb.fatals)
+		}
+		if mtb.helpers == 0 {
+			t.Error("expected the failure to be reported at the caller")
+		}
+	})
+
+	t.Run("ProviderExited", func(t *testing.T) {
+		mtb := &fatalTB{}
+		h := New(mtb)
+		_ = h.AddProvider("db", func(*Harness) (any, func() error, error) {
+			runtime.Goexit() // as t.FailNow does
+			return "conn", nil, nil
+		})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = h.Resource("db")
+		}()
+		<-done
+
+		if val, ok := h.Resource("db"); ok || val != nil {
+			t.Errorf("expected later lookups to fail, got %v (ok=%v)", val, ok)
+		}
+		if len(mtb.fatals) != 1 || !strings.Contains(mtb.fatals[0], "resource db: provider construction did not complete") {
+			t.Errorf("unexpected failure messages: %v", mtb.fatals)
+		}
+	})
+
+	t.Run("EnsureKeepsExisting", func(t *testing.T) {
+		h := New(t)
+		for _, value := range []string{"first", "second"} {
+			if err := h.EnsureProvider("db", func(*Harness) (any, func() error, error) {
+				return value, nil, nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if val, _ := h.Resource("db"); val != "first" {
+			t.Errorf("expected the first provider to be kept, got %v", val)
+		}
+	})
+}
+
+func TestHarness_Start(t *testing.T) {
+	t.Run("Concurrent", func(t *testing.T) {
+		h := New(t)
+		var started sync.WaitGroup
+		started.Add(2)
+		barrier := func(name string) Provider {
+			return func(*Harness) (any, func() error, error) {
+				started.Done()
+				// Deadlocks unless both providers run at the same time.
+				started.Wait()
+				return name, nil, nil
+			}
+		}
+		_ = h.AddProvider("auth", barrier("auth"), Eager())
+		_ = h.AddProvider("upstream", barrier("upstream"), Eager())
+		var sawAuth any
+		_ = h.AddProvider("server", func(h *Harness) (any, func() error, error) {
+			sawAuth, _ = h.Resource("auth")
+			return "server", nil, nil
+		}, Eager(), DependsOn("auth", "upstream"))
+
+		done := make(chan error, 1)
+		go func() { done <- h.Start() }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected independent providers to start concurrently")
+		}
+		if sawAuth != "auth" {
+			t.Errorf("expected dependency to be constructed first, got %v", sawAuth)
+		}
+	})
+
+	t.Run("FirstFailureCancels", func(t *testing.T) {
+		h := New(t)
+		var cleaned atomic.Bool
+		_ = h.AddProvider("ok", func(*Harness) (any, func() error, error) {
+			return "ok", func() error {
+				cleaned.Store(true)
+				return nil
+			}, nil
+		}, Eager())
+		_ = h.AddProvider("slow", func(h *Harness) (any, func() error, error) {
+			<-h.Context().Done()
+			return nil, nil, context.Cause(h.Context())
+		}, Eager())
+		_ = h.AddProvider("broken", func(*Harness) (any, func() error, error) {
+			return nil, nil, errors.New("port in use")
+		}, Eager())
+
+		err := h.Start()
+		if err == nil || !strings.Contains(err.Error(), "resource broken: construction failed: port in use") {
+			t.Fatalf("expected first failure, got %v", err)
+		}
+		if !cleaned.Load() {
+			t.Error("expected already started providers to be cleaned up")
+		}
+	})
+}
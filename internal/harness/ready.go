@@ -0,0 +1,72 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/next-trace/scg-test-kit/internal/probe"
+)
+
+// readiness is the probe configuration attached to a resource.
+type readiness struct {
+	probe   probe.Func
+	backoff probe.Backoff
+	timeout time.Duration
+}
+
+// ReadyWhen attaches a readiness probe to the resource. WaitReady retries the probe
+// with backoff until it succeeds or timeout elapses; zero values use the probe defaults.
+func ReadyWhen(fn probe.Func, backoff probe.Backoff, timeout time.Duration) ResourceOption {
+	return func(c *resourceConfig) {
+		c.ready = &readiness{probe: fn, backoff: backoff, timeout: timeout}
+	}
+}
+
+// WaitReady blocks until the readiness probes of all constructed resources that have
+// not been checked yet succeed. Probes run concurrently; the returned error lists
+// every resource that never became ready. Lazy resources are probed on construction.
+func (h *Harness) WaitReady() error {
+	type pending struct {
+		name  string
+		ready *readiness
+	}
+
+	h.mu.Lock()
+	var probes []pending
+	for _, n := range h.order {
+		if n.ready == nil || n.readyChecked || n.lazy != nil {
+			continue
+		}
+		n.readyChecked = true
+		probes = append(probes, pending{name: n.name, ready: n.ready})
+	}
+	h.mu.Unlock()
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	for _, p := range probes {
+		wg.Go(func() {
+			if err := p.ready.wait(h.ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("resource %s: %w", p.name, err))
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errors.Join(errs...)
+}
+
+func (r *readiness) wait(ctx context.Context) error {
+	return probe.Wait(ctx, r.probe, r.backoff, r.timeout)
+}
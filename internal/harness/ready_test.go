@@ -0,0 +1,70 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-test-kit/internal/probe"
+)
+
+func TestHarness_WaitReady(t *testing.T) {
+	fast := probe.Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+
+	t.Run("AllReady", func(t *testing.T) {
+		h := New(t)
+		attempts := 0
+		_ = h.AddResource("db", nil, nil, ReadyWhen(func(context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("starting")
+			}
+			return nil
+		}, fast, time.Second))
+
+		if err := h.WaitReady(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// Probes that already passed are not re-run.
+		if err := h.WaitReady(); err != nil || attempts != 3 {
+			t.Errorf("expected probe to run until ready once, got attempts=%d err=%v", attempts, err)
+		}
+	})
+
+	t.Run("NeverReady", func(t *testing.T) {
+		h := New(t)
+		_ = h.AddResource("db", nil, nil, ReadyWhen(func(context.Context) error { return nil }, fast, time.Second))
+		_ = h.AddResource("queue", nil, nil, ReadyWhen(func(context.Context) error {
+			return errors.New("connection refused")
+		}, fast, 20*time.Millisecond))
+
+		err := h.WaitReady()
+		if err == nil || !strings.Contains(err.Error(), "resource queue: not ready after") ||
+			!strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("expected per-resource diagnostic, got %v", err)
+		}
+		if strings.Contains(err.Error(), "resource db") {
+			t.Errorf("expected ready resource not to be reported, got %v", err)
+		}
+	})
+
+	t.Run("LazyProbedOnConstruction", func(t *testing.T) {
+		mtb := &fatalTB{}
+		h := New(mtb)
+		_ = h.AddProvider("db", func(*Harness) (any, func() error, error) {
+			return "conn", nil, nil
+		}, ReadyWhen(func(context.Context) error { return errors.New("down") }, fast, 10*time.Millisecond))
+
+		if err := h.WaitReady(); err != nil {
+			t.Fatalf("expected unconstructed provider to be skipped, got %v", err)
+		}
+		if _, ok := h.Resource("db"); ok {
+			t.Error("expected ok=false for a resource that never became ready")
+		}
+		if len(mtb.fatals) != 1 || !strings.Contains(mtb.fatals[0], "resource db: not ready after") {
+			t.Errorf("unexpected failure messages: %v", mtb.fatals)
+		}
+	})
+}
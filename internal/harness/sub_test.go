@@ -0,0 +1,70 @@
+package harness
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHarness_Sub(t *testing.T) {
+	var order []string
+	record := func(name string) func() error {
+		return func() error {
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	parent := New(t)
+	_ = parent.AddResource("db", "parent-db", record("parent-db"))
+	_ = parent.AddResource("cache", "parent-cache", nil)
+
+	t.Run("Child", func(t *testing.T) {
+		child := parent.Sub(t)
+		if child.T() != t {
+			t.Error("expected child to be bound to the subtest")
+		}
+		_ = child.AddResource("db", "child-db", record("child-db"))
+		// Dependencies may point at inherited resources.
+		if err := child.AddResource("repo", "repo", record("repo"), DependsOn("cache")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if val, _ := child.Resource("db"); val != "child-db" {
+			t.Errorf("expected child to shadow db, got %v", val)
+		}
+		if val, _ := child.Resource("cache"); val != "parent-cache" {
+			t.Errorf("expected cache from parent, got %v", val)
+		}
+		if val, _ := parent.Resource("db"); val != "parent-db" {
+			t.Errorf("expected parent db to be untouched, got %v", val)
+		}
+	})
+
+	if !slices.Equal(order, []string{"repo", "child-db"}) {
+		t.Errorf("expected only child cleanups after subtest, got %v", order)
+	}
+	parent.Cleanup()
+	if !slices.Equal(order, []string{"repo", "child-db", "parent-db"}) {
+		t.Errorf("expected parent cleanup last, got %v", order)
+	}
+}
+
+func TestHarness_SubProvider(t *testing.T) {
+	parent := New(t)
+	calls := 0
+	_ = parent.AddProvider("db", func(*Harness) (any, func() error, error) {
+		calls++
+		return "conn", nil, nil
+	})
+
+	for _, name := range []string{"a", "b"} {
+		t.Run(name, func(t *testing.T) {
+			if val, ok := parent.Sub(t).Resource("db"); !ok || val != "conn" {
+				t.Errorf("expected conn, got %v", val)
+			}
+		})
+	}
+	if calls != 1 {
+		t.Errorf("expected parent provider to be constructed once, got %d", calls)
+	}
+}
@@ -0,0 +1,162 @@
+package harness
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// ErrSuiteClosed is returned when a closed Suite is asked to register a resource.
+var ErrSuiteClosed = errors.New("suite closed")
+
+// Suite holds resources shared by all tests of a package. It is created in TestMain,
+// outlives individual tests, and tears its resources down in Close after m.Run returns.
+// Harnesses borrow suite resources read-only; the Suite keeps a reference count per
+// resource so leaked borrows are reported on Close.
+type Suite struct {
+	mu        sync.Mutex
+	nodes     map[string]*node
+	order     []*node
+	seq       int
+	refs      map[string]int
+	errs      []error
+	closed    bool
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewSuite creates an empty Suite.
+func NewSuite() *Suite {
+	return &Suite{
+		nodes: make(map[string]*node),
+		order: make([]*node, 0),
+		refs:  make(map[string]int),
+	}
+}
+
+// AddResource adds a named shared resource to the suite and registers its cleanup if provided.
+// Dependencies follow the same rules as Harness.AddResource.
+func (s *Suite) AddResource(name string, value any, cleanup func() error, opts ...ResourceOption) error {
+	cfg := newResourceConfig(opts)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSuiteClosed
+	}
+	if err := validateDeps(s.nodes, name, cfg.deps, nil); err != nil {
+		return err
+	}
+
+	n, ok := s.nodes[name]
+	if !ok {
+		n = &node{name: name}
+		s.nodes[name] = n
+		s.order = append(s.order, n)
+	}
+	s.seq++
+	n.seq = s.seq
+	n.value = value
+	n.deps = cfg.deps
+	addCleanup(n, name, contextCleanup(cleanup), cfg.cleanupTimeout)
+	return nil
+}
+
+// Refs returns the number of harnesses currently borrowing the named resource.
+func (s *Suite) Refs(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refs[name]
+}
+
+// acquire returns the named resource and increments its reference count.
+func (s *Suite) acquire(name string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, false
+	}
+	n, ok := s.nodes[name]
+	if !ok {
+		return nil, false
+	}
+	s.refs[name]++
+	return n.value, true
+}
+
+// has reports whether the named resource is registered and the suite is open.
+func (s *Suite) has(name string) bool {
+	_, ok := s.peek(name)
+	return ok
+}
+
+// peek returns the named resource without taking a reference.
+func (s *Suite) peek(name string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.nodes[name]
+	if !ok || s.closed {
+		return nil, false
+	}
+	return n.value, true
+}
+
+// release decrements the reference count of the named resource.
+func (s *Suite) release(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refs[name] > 0 {
+		s.refs[name]--
+	}
+}
+
+// Close tears down all shared resources in reverse topological order and returns
+// the joined cleanup errors. Panicking and timed-out cleanups are isolated the same
+// way as in Harness.Cleanup. Resources still borrowed by a harness are reported as
+// errors but torn down anyway. It is idempotent.
+func (s *Suite) Close() error {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		var leaked []string
+		for name, refs := range s.refs {
+			if refs > 0 {
+				leaked = append(leaked, name)
+			}
+		}
+		slices.Sort(leaked)
+		for _, name := range leaked {
+			s.errs = append(s.errs, fmt.Errorf("resource %s still borrowed by %d harness(es)", name, s.refs[name]))
+		}
+		ops := teardownOrder(s.order)
+		errs := s.errs
+		s.mu.Unlock()
+
+		errs = append(errs, joinCleanupErrors(runCleanups(ops, 0)))
+		s.closeErr = errors.Join(errs...)
+	})
+	return s.closeErr
+}
+
+// Runner is implemented by *testing.M.
+type Runner interface {
+	Run() int
+}
+
+// Run runs the tests with m, closes the suite, and returns the exit code for os.Exit.
+// A failing Close turns a successful run into exit code 1.
+func (s *Suite) Run(m Runner) int {
+	code := m.Run()
+	if err := s.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "suite cleanup failed: %v\n", err)
+		if code == 0 {
+			code = 1
+		}
+	}
+	return code
+}
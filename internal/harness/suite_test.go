@@ -0,0 +1,70 @@
+package harness
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type runnerFunc func() int
+
+func (f runnerFunc) Run() int { return f() }
+
+func TestSuite_Close(t *testing.T) {
+	t.Run("TeardownOrder", func(t *testing.T) {
+		var order []string
+		s := NewSuite()
+		_ = s.AddResource("db", nil, func() error {
+			order = append(order, "db")
+			return nil
+		})
+		_ = s.AddResource("seed", nil, func() error {
+			order = append(order, "seed")
+			return errors.New("boom")
+		}, DependsOn("db"))
+
+		err := s.Close()
+		if err == nil || !strings.Contains(err.Error(), "cleanup seed failed: boom") {
+			t.Errorf("expected joined cleanup error, got %v", err)
+		}
+		if !slices.Equal(order, []string{"seed", "db"}) {
+			t.Errorf("expected [seed db], got %v", order)
+		}
+		if !errors.Is(s.AddResource("late", nil, nil), ErrSuiteClosed) {
+			t.Error("expected ErrSuiteClosed after Close")
+		}
+	})
+
+	t.Run("LeakedBorrow", func(t *testing.T) {
+		s := NewSuite()
+		_ = s.AddResource("db", "conn", nil)
+
+		h := New(&mockTB{})
+		h.UseSuite(s)
+		if val, ok := h.Resource("db"); !ok || val != "conn" {
+			t.Fatalf("expected conn, got %v", val)
+		}
+		// A second lookup must not take another reference.
+		_, _ = h.Resource("db")
+		if refs := s.Refs("db"); refs != 1 {
+			t.Fatalf("expected 1 ref, got %d", refs)
+		}
+
+		err := s.Close()
+		if err == nil || !strings.Contains(err.Error(), "resource db still borrowed by 1 harness(es)") {
+			t.Errorf("expected leaked borrow to be reported, got %v", err)
+		}
+		if _, ok := h.Resource("db"); ok {
+			t.Error("expected closed suite resources to be unavailable")
+		}
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		s := NewSuite()
+		_ = s.AddResource("db", nil, func() error { return errors.New("boom") })
+		if code := s.Run(runnerFunc(func() int { return 0 })); code != 1 {
+			t.Errorf("expected failing Close to set exit code 1, got %d", code)
+		}
+	})
+}
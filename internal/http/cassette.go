@@ -0,0 +1,418 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// CassetteMode selects whether a Cassette records or replays interactions.
+type CassetteMode int
+
+const (
+	// ModeOnce replays the cassette if the file exists and records it otherwise.
+	ModeOnce CassetteMode = iota
+	// ModeReplay serves recorded interactions and fails requests that have none.
+	ModeReplay
+	// ModeRecord sends every request upstream and overwrites the cassette.
+	ModeRecord
+)
+
+// CassetteModeEnv overrides the mode of every cassette when set to "record",
+// "replay" or "once", e.g. to re-record all cassettes of a package.
+const CassetteModeEnv = "TESTKIT_CASSETTE_MODE"
+
+// redacted replaces header values removed by RedactHeaders.
+const redacted = "REDACTED"
+
+// Match fields compared between a request and a recorded interaction.
+const (
+	MatchMethod = "method"
+	MatchURL    = "url"
+	MatchPath   = "path" // path and query, ignoring scheme and host
+	MatchBody   = "body"
+)
+
+// CassetteOption configures a Cassette.
+type CassetteOption func(*cassetteConfig)
+
+type cassetteConfig struct {
+	mode       CassetteMode
+	upstream   http.RoundTripper
+	match      []string
+	headers    []string
+	redactHead []string
+	redactBody func(string) string
+}
+
+// WithMode sets the cassette mode. CassetteModeEnv takes precedence.
+func WithMode(mode CassetteMode) CassetteOption {
+	return func(c *cassetteConfig) {
+		c.mode = mode
+	}
+}
+
+// Upstream sets the transport used while recording; the default is http.DefaultTransport.
+func Upstream(rt http.RoundTripper) CassetteOption {
+	return func(c *cassetteConfig) {
+		c.upstream = rt
+	}
+}
+
+// MatchOn sets the fields a request must share with a recorded interaction:
+// MatchMethod, MatchURL, MatchPath or MatchBody. The default is method and URL;
+// use MatchPath when recording against a local stand-in on a random port.
+func MatchOn(fields ...string) CassetteOption {
+	return func(c *cassetteConfig) {
+		c.match = fields
+	}
+}
+
+// MatchHeaders additionally requires the named request headers to be equal.
+func MatchHeaders(names ...string) CassetteOption {
+	return func(c *cassetteConfig) {
+		for _, name := range names {
+			c.headers = append(c.headers, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// RedactHeaders replaces the values of the named request and response headers
+// before interactions are written to disk.
+func RedactHeaders(names ...string) CassetteOption {
+	return func(c *cassetteConfig) {
+		for _, name := range names {
+			c.redactHead = append(c.redactHead, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// RedactBody rewrites request and response bodies before they are written to disk.
+// Incoming requests are rewritten the same way before matching.
+func RedactBody(fn func(body string) string) CassetteOption {
+	return func(c *cassetteConfig) {
+		c.redactBody = fn
+	}
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the stored form of a request.
+type RecordedRequest struct {
+	Method string       `json:"method"`
+	URL    string       `json:"url"`
+	Header http.Header  `json:"header,omitempty"`
+	Body   recordedBody `json:"body,omitempty"`
+}
+
+// RecordedResponse is the stored form of a response.
+type RecordedResponse struct {
+	Status int          `json:"status"`
+	Header http.Header  `json:"header,omitempty"`
+	Body   recordedBody `json:"body,omitempty"`
+}
+
+// recordedBody is stored as text when it is valid UTF-8 and as base64 otherwise.
+type recordedBody string
+
+func (b recordedBody) MarshalJSON() ([]byte, error) {
+	if utf8.ValidString(string(b)) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString([]byte(b))})
+}
+
+func (b *recordedBody) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = recordedBody(text)
+		return nil
+	}
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	*b = recordedBody(raw)
+	return err
+}
+
+// Cassette is an http.RoundTripper that records interactions to a file or replays
+// them from it.
+type Cassette struct {
+	path string
+	cfg  cassetteConfig
+
+	mu           sync.Mutex
+	recording    bool
+	interactions []Interaction
+	used         []bool
+	unmatched    []string
+}
+
+// NewCassette opens the cassette at path. In replay mode the file must exist.
+func NewCassette(path string, opts ...CassetteOption) (*Cassette, error) {
+	cfg := cassetteConfig{match: []string{MatchMethod, MatchURL}, upstream: http.DefaultTransport}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	switch env := os.Getenv(CassetteModeEnv); env {
+	case "":
+	case "once":
+		cfg.mode = ModeOnce
+	case "replay":
+		cfg.mode = ModeReplay
+	case "record":
+		cfg.mode = ModeRecord
+	default:
+		return nil, fmt.Errorf("%s: unknown mode %q", CassetteModeEnv, env)
+	}
+	for _, field := range cfg.match {
+		if !slices.Contains([]string{MatchMethod, MatchURL, MatchPath, MatchBody}, field) {
+			return nil, fmt.Errorf("unknown match field %q", field)
+		}
+	}
+
+	c := &Cassette{path: path, cfg: cfg}
+	if cfg.mode == ModeRecord {
+		c.recording = true
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && cfg.mode == ModeOnce {
+		c.recording = true
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.interactions); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	c.used = make([]bool, len(c.interactions))
+	return c, nil
+}
+
+// Recording reports whether the cassette records rather than replays.
+func (c *Cassette) Recording() bool {
+	return c.recording
+}
+
+// Client returns an http.Client that sends requests through the cassette.
+func (c *Cassette) Client() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+// Interactions returns the interactions recorded or loaded so far.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.interactions)
+}
+
+// RoundTrip records or replays req.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	out, body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := c.recordRequest(out, body)
+
+	if !c.recording {
+		return c.replay(req, recorded)
+	}
+
+	resp, err := c.cfg.upstream.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	resp.Request = req
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			Status: resp.StatusCode,
+			Header: c.redactHeader(resp.Header),
+			Body:   recordedBody(c.redactText(string(respBody))),
+		},
+	})
+	c.mu.Unlock()
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	found, last := -1, -1
+	for i, in := range c.interactions {
+		if !c.matches(in.Request, recorded) {
+			continue
+		}
+		if !c.used[i] {
+			found = i
+			break
+		}
+		last = i
+	}
+	if found < 0 {
+		// Repeat the last matching interaction once all of them have been played
+		found = last
+	}
+	if found < 0 {
+		call := req.Method + " " + req.URL.String()
+		c.unmatched = append(c.unmatched, call)
+		return nil, fmt.Errorf("cassette %s: no recorded interaction matches %s", c.path, call)
+	}
+	c.used[found] = true
+	in := c.interactions[found].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        in.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(string(in.Body))),
+		ContentLength: int64(len(in.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (c *Cassette) matches(recorded, req RecordedRequest) bool {
+	for _, field := range c.cfg.match {
+		switch field {
+		case MatchMethod:
+			if recorded.Method != req.Method {
+				return false
+			}
+		case MatchURL:
+			if recorded.URL != req.URL {
+				return false
+			}
+		case MatchPath:
+			if requestURI(recorded.URL) != requestURI(req.URL) {
+				return false
+			}
+		case MatchBody:
+			if recorded.Body != req.Body {
+				return false
+			}
+		}
+	}
+	for _, name := range c.cfg.headers {
+		if !slices.Equal(recorded.Header.Values(name), req.Header.Values(name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Verify returns an error listing requests that had no recorded interaction.
+func (c *Cassette) Verify() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.unmatched) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d request(s) had no recorded interaction in %s:\n  %s", len(c.unmatched), c.path, strings.Join(c.unmatched, "\n  "))
+}
+
+// Save writes the recorded interactions to the cassette file. It does nothing when
+// replaying.
+func (c *Cassette) Save() error {
+	if !c.recording {
+		return nil
+	}
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+func (c *Cassette) recordRequest(req *http.Request, body []byte) RecordedRequest {
+	return RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: c.redactHeader(req.Header),
+		Body:   recordedBody(c.redactText(string(body))),
+	}
+}
+
+func (c *Cassette) redactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	out := header.Clone()
+	for _, name := range c.cfg.redactHead {
+		if values := out[name]; len(values) > 0 {
+			out[name] = slices.Repeat([]string{redacted}, len(values))
+		}
+	}
+	return out
+}
+
+func (c *Cassette) redactText(body string) string {
+	if c.cfg.redactBody == nil {
+		return body
+	}
+	return c.cfg.redactBody(body)
+}
+
+// requestURI returns the path and query of rawURL.
+func requestURI(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.RequestURI()
+}
+
+// readBody reads and closes the request body. It returns a clone of req carrying
+// the body again so that it can still be sent; req itself is left unmodified, as
+// the http.RoundTripper contract requires.
+func readBody(req *http.Request) (*http.Request, []byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	return out, body, nil
+}
@@ -0,0 +1,150 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCassette(t *testing.T) {
+	t.Setenv(CassetteModeEnv, "")
+	path := filepath.Join(t.TempDir(), "cassettes", "api.json")
+	secret := regexp.MustCompile(`"password":"[^"]*"`)
+	opts := []CassetteOption{
+		MatchOn(MatchMethod, MatchPath, MatchBody),
+		MatchHeaders("X-Tenant"),
+		RedactHeaders("Authorization", "Set-Cookie"),
+		RedactBody(func(body string) string { return secret.ReplaceAllString(body, `"password":"***"`) }),
+	}
+
+	calls := 0
+	srv, cleanup := NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"call":` + strings.Repeat("1", calls) + `}`))
+	}))
+	send := func(t *testing.T, c *Cassette, tenant string) *Response {
+		t.Helper()
+		return NewClient(t, srv.BaseURL(), c.Client()).Post("/login").
+			Header("X-Tenant", tenant).
+			BearerToken("t0k3n").
+			Body([]byte(`{"user":"alice","password":"hunter2"}`), "application/json").
+			Do()
+	}
+
+	t.Run("Record", func(t *testing.T) {
+		c, err := NewCassette(path, opts...)
+		if err != nil || !c.Recording() {
+			t.Fatalf("expected missing cassette to record, got %v", err)
+		}
+		send(t, c, "a").ExpectJSONEqual(`{"call": 1}`)
+		send(t, c, "a").ExpectJSONEqual(`{"call": 11}`)
+		send(t, c, "b").ExpectJSONEqual(`{"call": 111}`)
+		if err := c.Save(); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, leaked := range []string{"t0k3n", "hunter2", "session=secret"} {
+			if strings.Contains(string(data), leaked) {
+				t.Errorf("expected %q to be redacted from the cassette", leaked)
+			}
+		}
+		if !strings.Contains(string(data), redacted) {
+			t.Error("expected redacted placeholder in the cassette")
+		}
+	})
+	_ = cleanup()
+
+	t.Run("Replay", func(t *testing.T) {
+		c, err := NewCassette(path, opts...)
+		if err != nil || c.Recording() {
+			t.Fatalf("expected existing cassette to replay, got %v", err)
+		}
+		send(t, c, "b").ExpectJSONEqual(`{"call": 111}`)
+		send(t, c, "a").ExpectJSONEqual(`{"call": 1}`).ExpectContentType("application/json")
+		send(t, c, "a").ExpectJSONEqual(`{"call": 11}`)
+		send(t, c, "a").ExpectJSONEqual(`{"call": 11}`)
+		if calls != 3 {
+			t.Errorf("expected replay not to reach the server, got %d calls", calls)
+		}
+		if err := c.Verify(); err != nil {
+			t.Errorf("expected all requests to match, got %v", err)
+		}
+
+		if _, err := c.Client().Get(srv.BaseURL() + "/other"); err == nil {
+			t.Error("expected unmatched request to fail")
+		}
+		if err := c.Verify(); err == nil || !strings.Contains(err.Error(), "GET "+srv.BaseURL()+"/other") {
+			t.Errorf("expected unmatched request to be reported, got %v", err)
+		}
+	})
+
+	t.Run("Modes", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.json")
+		if _, err := NewCassette(missing, WithMode(ModeReplay)); err == nil {
+			t.Error("expected replay of a missing cassette to fail")
+		}
+		if c, err := NewCassette(path, WithMode(ModeRecord)); err != nil || !c.Recording() {
+			t.Errorf("expected record mode to ignore the existing cassette, got %v", err)
+		}
+		t.Setenv(CassetteModeEnv, "record")
+		if c, err := NewCassette(path, WithMode(ModeReplay)); err != nil || !c.Recording() {
+			t.Errorf("expected environment to override the mode, got %v", err)
+		}
+		t.Setenv(CassetteModeEnv, "sometimes")
+		if _, err := NewCassette(path); err == nil {
+			t.Error("expected unknown mode to fail")
+		}
+	})
+}
+
+func TestCassette_RequestUnmodified(t *testing.T) {
+	srv, cleanup := NewServer(t, http.HandlerFunc(echoHandler))
+	defer func() { _ = cleanup() }()
+	c, err := NewCassette(filepath.Join(t.TempDir(), "api.json"), WithMode(ModeRecord))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := &closeTrackingBody{Reader: strings.NewReader("hi")}
+	req, err := http.NewRequest(http.MethodPost, srv.BaseURL()+"/", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if req.Body != body || !body.closed {
+		t.Errorf("expected the request body to be closed and left in place, got %T", req.Body)
+	}
+	if resp.Request != req {
+		t.Error("expected the response to refer to the caller's request")
+	}
+	if got := c.Interactions()[0].Request.Body; got != "hi" {
+		t.Errorf("expected the body to be sent and recorded, got %q", got)
+	}
+}
+
+func TestRecordedBody(t *testing.T) {
+	for _, body := range []recordedBody{"", "text", "\xff\x00binary"} {
+		data, err := body.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got recordedBody
+		if err := got.UnmarshalJSON(data); err != nil || got != body {
+			t.Errorf("expected %q to round-trip, got %q (%v)", body, got, err)
+		}
+	}
+}
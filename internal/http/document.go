@@ -0,0 +1,355 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/next-trace/scg-test-kit/internal/dom"
+)
+
+// Document is a parsed HTML response. Links and forms found in it are resolved
+// against the page URL and followed through the client that fetched it, so a
+// session keeps its cookies across page loads and form submissions.
+type Document struct {
+	t      testing.TB
+	client *Client
+	base   *url.URL
+	root   *dom.Node
+}
+
+// HTML parses the body as an HTML document.
+func (r *Response) HTML() *Document {
+	d := &Document{t: r.t, client: r.client, root: dom.Parse(string(r.Body))}
+	if r.Raw != nil && r.Raw.Request != nil {
+		d.base = r.Raw.Request.URL
+	}
+	if base := d.find("base[href]"); len(base) > 0 {
+		d.base = d.resolve(base[0].AttrOr("href", ""))
+	}
+	return d
+}
+
+// Root returns the document node.
+func (d *Document) Root() *dom.Node {
+	return d.root
+}
+
+// Find returns the elements matching selector in document order.
+// An invalid selector fails the test.
+func (d *Document) Find(selector string) []*dom.Node {
+	d.t.Helper()
+	return d.find(selector)
+}
+
+func (d *Document) find(selector string) []*dom.Node {
+	sel, err := dom.Compile(selector)
+	if err != nil {
+		d.t.Fatalf("%v", err)
+		return nil
+	}
+	return d.root.Find(sel)
+}
+
+// First returns the first element matching selector, failing the test if there is none.
+func (d *Document) First(selector string) *dom.Node {
+	d.t.Helper()
+	found := d.find(selector)
+	if len(found) == 0 {
+		d.t.Fatalf("no element matches %q", selector)
+		return nil
+	}
+	return found[0]
+}
+
+// ByID returns the element with the given id, failing the test if there is none.
+func (d *Document) ByID(id string) *dom.Node {
+	d.t.Helper()
+	n := d.root.ByID(id)
+	if n == nil {
+		d.t.Fatalf("no element with id %q", id)
+	}
+	return n
+}
+
+// Links returns the absolute targets of all <a href> elements in document order.
+func (d *Document) Links() []string {
+	d.t.Helper()
+	var links []string
+	for _, a := range d.find("a[href]") {
+		links = append(links, d.resolve(a.AttrOr("href", "")).String())
+	}
+	return links
+}
+
+// Follow requests the target of the first link matching selector.
+func (d *Document) Follow(selector string) *Response {
+	d.t.Helper()
+	a := d.First(selector)
+	if a == nil {
+		return nil
+	}
+	href, ok := a.Attr("href")
+	if !ok {
+		d.t.Fatalf("element %q has no href", selector)
+		return nil
+	}
+	return d.client.Get(d.resolve(href).String()).Do()
+}
+
+// Form returns the form matching selector with its fields filled in as a browser
+// would submit them, failing the test if there is no such form.
+func (d *Document) Form(selector string) *Form {
+	d.t.Helper()
+	n := d.First(selector)
+	if n == nil {
+		return nil
+	}
+	if n.Tag != "form" {
+		d.t.Fatalf("element %q is a <%s>, not a <form>", selector, n.Tag)
+		return nil
+	}
+	f := &Form{
+		doc:     d,
+		node:    n,
+		action:  d.resolve(n.AttrOr("action", "")),
+		method:  strings.ToUpper(n.AttrOr("method", http.MethodGet)),
+		enctype: strings.ToLower(n.AttrOr("enctype", "application/x-www-form-urlencoded")),
+		fields:  make(map[string]int),
+	}
+	f.collect()
+	return f
+}
+
+// resolve returns ref relative to the page URL.
+func (d *Document) resolve(ref string) *url.URL {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		d.t.Fatalf("invalid URL %q: %v", ref, err)
+		return &url.URL{}
+	}
+	if d.base == nil {
+		return u
+	}
+	return d.base.ResolveReference(u)
+}
+
+// Form is an HTML form with the values it will submit.
+type Form struct {
+	doc     *Document
+	node    *dom.Node
+	action  *url.URL
+	method  string
+	enctype string
+	values  []formValue    // in document order, as a browser submits them
+	fields  map[string]int // position of the first control of each name
+	files   []multipartFile
+}
+
+// formValue is an entry of the form data set with the position of its control.
+type formValue struct {
+	formField
+	pos int
+}
+
+// collect gathers the successful controls of the form, following the rules
+// browsers use to build the form data set.
+func (f *Form) collect() {
+	for pos, n := range f.doc.findIn(f.node, "input, select, textarea") {
+		name, ok := n.Attr("name")
+		if !ok || name == "" {
+			continue
+		}
+		if _, disabled := n.Attr("disabled"); disabled {
+			continue
+		}
+		typ := strings.ToLower(n.AttrOr("type", "text"))
+		if typ == "submit" || typ == "button" || typ == "image" || typ == "reset" {
+			// Only the button used to submit is sent; Submit uses none
+			continue
+		}
+		if _, seen := f.fields[name]; !seen {
+			f.fields[name] = pos
+		}
+		add := func(value string) {
+			f.values = append(f.values, formValue{formField{name: name, value: value}, pos})
+		}
+		switch {
+		case n.Tag == "textarea":
+			add(strings.TrimPrefix(n.RawText(), "\n"))
+		case n.Tag == "select":
+			for _, value := range selectedOptions(f.doc, n) {
+				add(value)
+			}
+		case typ == "file":
+			// Files are attached with File
+		case typ == "checkbox" || typ == "radio":
+			if _, checked := n.Attr("checked"); checked {
+				add(n.AttrOr("value", "on"))
+			}
+		default:
+			add(n.AttrOr("value", ""))
+		}
+	}
+}
+
+func selectedOptions(d *Document, sel *dom.Node) []string {
+	options := d.findIn(sel, "option")
+	var selected []string
+	for _, o := range options {
+		if _, ok := o.Attr("selected"); ok {
+			selected = append(selected, optionValue(o))
+		}
+	}
+	if _, multiple := sel.Attr("multiple"); multiple {
+		return selected
+	}
+	if len(selected) > 0 {
+		// A single select submits its last selected option, as browsers do
+		return selected[len(selected)-1:]
+	}
+	if len(options) > 0 {
+		return []string{optionValue(options[0])}
+	}
+	return nil
+}
+
+func optionValue(o *dom.Node) string {
+	if val, ok := o.Attr("value"); ok {
+		return val
+	}
+	return o.Text()
+}
+
+func (d *Document) findIn(n *dom.Node, selector string) []*dom.Node {
+	sel, err := dom.Compile(selector)
+	if err != nil {
+		panic(err) // selectors used internally are constants
+	}
+	return n.Find(sel)
+}
+
+// Action returns the absolute URL the form submits to.
+func (f *Form) Action() string {
+	return f.action.String()
+}
+
+// Method returns the upper-case HTTP method of the form.
+func (f *Form) Method() string {
+	return f.method
+}
+
+// Values returns a copy of the values the form will submit, excluding files.
+func (f *Form) Values() url.Values {
+	values := make(url.Values)
+	for _, v := range f.values {
+		values.Add(v.name, v.value)
+	}
+	return values
+}
+
+// insert adds a value for name after the values of the controls that precede the
+// field in the document, where a browser would place it.
+func (f *Form) insert(name, value string) {
+	pos := f.fields[name]
+	for _, v := range f.values {
+		if v.name == name {
+			pos = max(pos, v.pos)
+		}
+	}
+	i := len(f.values)
+	for i > 0 && f.values[i-1].pos > pos {
+		i--
+	}
+	f.values = slices.Insert(f.values, i, formValue{formField{name: name, value: value}, pos})
+}
+
+func (f *Form) remove(name string) {
+	f.values = slices.DeleteFunc(f.values, func(v formValue) bool { return v.name == name })
+}
+
+// encode renders the values as application/x-www-form-urlencoded, keeping their order.
+func (f *Form) encode() string {
+	var b strings.Builder
+	for i, v := range f.values {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.QueryEscape(v.name))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(v.value))
+	}
+	return b.String()
+}
+
+// Set replaces the values of the field name. Setting a field the form does not
+// contain is reported as an error, since a browser could not submit it.
+func (f *Form) Set(name, value string) *Form {
+	f.doc.t.Helper()
+	f.check(name)
+	f.remove(name)
+	f.insert(name, value)
+	return f
+}
+
+// Add appends a value to the field name, e.g. to check several checkboxes.
+func (f *Form) Add(name, value string) *Form {
+	f.doc.t.Helper()
+	f.check(name)
+	f.insert(name, value)
+	return f
+}
+
+// Unset removes the field name, e.g. to uncheck a checkbox.
+func (f *Form) Unset(name string) *Form {
+	f.doc.t.Helper()
+	f.check(name)
+	f.remove(name)
+	return f
+}
+
+// File attaches a file to the field name. It requires a multipart form.
+func (f *Form) File(name, filename string, content []byte) *Form {
+	f.doc.t.Helper()
+	f.check(name)
+	if f.enctype != "multipart/form-data" {
+		f.doc.t.Errorf("form %s: cannot attach file %s to a %s form", f.Action(), name, f.enctype)
+	}
+	f.files = append(f.files, multipartFile{field: name, filename: filename, content: content})
+	return f
+}
+
+func (f *Form) check(name string) {
+	f.doc.t.Helper()
+	if _, ok := f.fields[name]; !ok {
+		f.doc.t.Errorf("form %s has no field %q", f.Action(), name)
+	}
+}
+
+// Submit sends the form through the client that fetched the page. GET forms send
+// their values as the query string; other forms send them as the body, encoded
+// according to the enctype attribute. Fields are sent in document order.
+func (f *Form) Submit() *Response {
+	f.doc.t.Helper()
+	c := f.doc.client
+	if f.method == http.MethodGet {
+		target := *f.action
+		target.RawQuery = f.encode()
+		return c.Get(target.String()).Do()
+	}
+
+	req := c.Method(f.method, f.Action())
+	if f.enctype != "multipart/form-data" {
+		return req.Body([]byte(f.encode()), "application/x-www-form-urlencoded").Do()
+	}
+	// Set directly so that a form without fields still sends an empty multipart body
+	req.fields = make([]formField, 0, len(f.values))
+	for _, v := range f.values {
+		req.fields = append(req.fields, v.formField)
+	}
+	req.files = slices.Clone(f.files)
+	return req.Do()
+}
@@ -0,0 +1,193 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const adminPage = `<html><body>
+<nav><a href="users">Users</a> <a href="/logout">Log out</a></nav>
+<form id="profile" action="/admin/profile" method="post">
+  <input type="hidden" name="csrf" value="%s">
+  <input name="email" value="old@example.com">
+  <input type="checkbox" name="notify" checked>
+  <input type="checkbox" name="beta" value="yes">
+  <input name="locked" value="x" disabled>
+  <select name="role"><option value="user">User<option value="admin" selected>Admin</select>
+  <textarea name="bio">
+hello</textarea>
+  <button type="submit" name="save">Save</button>
+</form>
+<form id="search" action="/admin/search"><input name="q"></form>
+<form id="upload" action="/admin/upload" method="post" enctype="multipart/form-data">
+  <input type="hidden" name="csrf" value="%s"><input type="file" name="avatar">
+</form>
+</body></html>`
+
+func adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/", func(w http.ResponseWriter, r *http.Request) {
+		token := "fresh"
+		if c, err := r.Cookie("csrf"); err == nil {
+			token = c.Value
+		}
+		http.SetCookie(w, &http.Cookie{Name: "csrf", Value: token, Path: "/"})
+		_, _ = fmt.Fprintf(w, adminPage, token, token)
+	})
+	echoForm := func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("csrf"); err != nil || r.FormValue("csrf") != c.Value {
+			http.Error(w, "bad csrf token", http.StatusForbidden)
+			return
+		}
+		_ = r.ParseMultipartForm(1 << 20)
+		_, _ = fmt.Fprint(w, r.PostForm.Encode())
+		if r.MultipartForm != nil {
+			f, header, err := r.FormFile("avatar")
+			if err == nil {
+				data, _ := io.ReadAll(f)
+				_, _ = fmt.Fprintf(w, " %s=%s", header.Filename, data)
+			}
+		}
+	}
+	mux.HandleFunc("POST /admin/profile", echoForm)
+	mux.HandleFunc("POST /admin/upload", echoForm)
+	mux.HandleFunc("GET /admin/search", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, r.URL.RawQuery)
+	})
+	return mux
+}
+
+func TestDocument(t *testing.T) {
+	srv, cleanup := NewServer(t, adminHandler())
+	defer func() { _ = cleanup() }()
+	s := NewSession(t, "admin", srv.BaseURL(), srv.Client())
+	doc := s.Get("/admin/").Do().ExpectStatus(http.StatusOK).HTML()
+
+	t.Run("Query", func(t *testing.T) {
+		links := doc.Links()
+		if len(links) != 2 || links[0] != srv.BaseURL()+"/admin/users" || links[1] != srv.BaseURL()+"/logout" {
+			t.Errorf("expected resolved links, got %v", links)
+		}
+		if got := doc.First("nav a").Text(); got != "Users" {
+			t.Errorf("expected first link text, got %q", got)
+		}
+		if len(doc.Find("form")) != 3 || doc.ByID("search").Tag != "form" {
+			t.Error("expected forms to be found")
+		}
+		if got := doc.Follow("nav a").Text(); !strings.Contains(got, `<form id="profile"`) {
+			t.Errorf("expected link to be followed, got %q", got)
+		}
+	})
+
+	t.Run("Values", func(t *testing.T) {
+		f := doc.Form("#profile")
+		if f.Method() != http.MethodPost || f.Action() != srv.BaseURL()+"/admin/profile" {
+			t.Errorf("unexpected form target %s %s", f.Method(), f.Action())
+		}
+		got := f.Values().Encode()
+		want := "bio=hello&csrf=fresh&email=old%40example.com&notify=on&role=admin"
+		if got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	})
+
+	t.Run("SubmitPost", func(t *testing.T) {
+		resp := doc.Form("#profile").
+			Set("email", "new@example.com").
+			Add("beta", "yes").
+			Unset("notify").
+			Submit().
+			ExpectStatus(http.StatusOK)
+		want := "beta=yes&bio=hello&csrf=fresh&email=new%40example.com&role=admin"
+		if resp.Text() != want {
+			t.Errorf("expected %s, got %s", want, resp.Text())
+		}
+
+		other := NewSession(t, "other", srv.BaseURL(), srv.Client())
+		other.SetCookie(&http.Cookie{Name: "csrf", Value: "stolen"})
+		doc.client = other.Client
+		doc.Form("#profile").Submit().ExpectStatus(http.StatusForbidden)
+		doc.client = s.Client
+	})
+
+	t.Run("SubmitGet", func(t *testing.T) {
+		if got := doc.Form("#search").Set("q", "a b").Submit().Text(); got != "q=a+b" {
+			t.Errorf("expected values in the query, got %q", got)
+		}
+	})
+
+	t.Run("SubmitMultipart", func(t *testing.T) {
+		got := doc.Form("#upload").File("avatar", "me.png", []byte("png")).Submit().ExpectStatus(http.StatusOK).Text()
+		if got != "csrf=fresh me.png=png" {
+			t.Errorf("unexpected multipart submission %q", got)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		mockT := &errorfTB{TB: t}
+		d := *doc
+		d.t = mockT
+		d.Form("#profile").Set("missing", "x").File("email", "a.txt", nil)
+		if len(mockT.errors) != 2 {
+			t.Errorf("expected unknown field and wrong enctype to be reported, got %q", mockT.errors)
+		}
+
+		fatalT := &mockTB{TB: t}
+		d.t = fatalT
+		if d.First("table") != nil || !fatalT.failed {
+			t.Error("expected missing element to fail the test")
+		}
+	})
+}
+
+const orderPage = `<form id="post" action="/order" method="post">
+  <input name="zeta" value="1">
+  <input type="checkbox" name="alpha" value="a">
+  <input name="mid" value="2">
+  <input type="checkbox" name="alpha" value="b" checked>
+</form>
+<form id="get" action="/order"><input name="z" value="1"><input name="a" value="2"></form>
+<form id="multi" action="/order" method="post" enctype="multipart/form-data">
+  <input name="zeta" value="1"><input name="alpha" value="2">
+</form>`
+
+func TestForm_DocumentOrder(t *testing.T) {
+	srv, cleanup := NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.RawQuery == "":
+			_, _ = fmt.Fprint(w, orderPage)
+		case r.Method == http.MethodGet:
+			_, _ = fmt.Fprint(w, r.URL.RawQuery)
+		case strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data"):
+			reader, _ := r.MultipartReader()
+			for part, err := reader.NextPart(); err == nil; part, err = reader.NextPart() {
+				value, _ := io.ReadAll(part)
+				_, _ = fmt.Fprintf(w, "%s=%s;", part.FormName(), value)
+			}
+		default:
+			_, _ = io.Copy(w, r.Body)
+		}
+	}))
+	defer func() { _ = cleanup() }()
+	doc := NewClient(t, srv.BaseURL(), srv.Client()).Get("/order").Do().HTML()
+
+	for _, tc := range []struct {
+		name string
+		form *Form
+		want string
+	}{
+		{"Collected", doc.Form("#post"), "zeta=1&mid=2&alpha=b"},
+		{"Added", doc.Form("#post").Add("alpha", "a"), "zeta=1&mid=2&alpha=b&alpha=a"},
+		{"Set", doc.Form("#post").Unset("alpha").Set("zeta", "3").Add("alpha", "a"), "zeta=3&alpha=a&mid=2"},
+		{"Get", doc.Form("#get"), "z=1&a=2"},
+		{"Multipart", doc.Form("#multi"), "zeta=1;alpha=2;"},
+	} {
+		if got := tc.form.Submit().Text(); got != tc.want {
+			t.Errorf("%s: expected fields in document order %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"mime"
+	"slices"
+	"strings"
+
+	"github.com/next-trace/scg-test-kit/internal/diff"
+	"github.com/next-trace/scg-test-kit/internal/snapshot"
+)
+
+// maxBodyInMessage bounds how much of a body is quoted in failure messages.
+const maxBodyInMessage = 1024
+
+// ExpectStatus reports an error if the status code is not status.
+func (r *Response) ExpectStatus(status int) *Response {
+	r.t.Helper()
+	if r.StatusCode != status {
+		r.t.Errorf("%s: expected status %d, got %d\nbody: %s", r.describe(), status, r.StatusCode, r.quoteBody())
+	}
+	return r
+}
+
+// ExpectHeader reports an error if the header key does not have value.
+func (r *Response) ExpectHeader(key, value string) *Response {
+	r.t.Helper()
+	values := r.Header.Values(key)
+	if !slices.Contains(values, value) {
+		if len(values) == 0 {
+			r.t.Errorf("%s: expected header %s: %q, header missing", r.describe(), key, value)
+		} else {
+			r.t.Errorf("%s: expected header %s: %q, got %q", r.describe(), key, value, values)
+		}
+	}
+	return r
+}
+
+// ExpectContentType reports an error if the media type differs from contentType.
+// Parameters such as charset are ignored unless contentType includes them.
+func (r *Response) ExpectContentType(contentType string) *Response {
+	r.t.Helper()
+	got := r.Header.Get("Content-Type")
+	wantType, wantParams, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		r.t.Errorf("invalid expected content type %q: %v", contentType, err)
+		return r
+	}
+	gotType, gotParams, _ := mime.ParseMediaType(got)
+	ok := gotType == wantType
+	for key, value := range wantParams {
+		ok = ok && gotParams[key] == value
+	}
+	if !ok {
+		r.t.Errorf("%s: expected Content-Type %q, got %q", r.describe(), contentType, got)
+	}
+	return r
+}
+
+// ExpectJSONEqual reports an error, with a diff, if the body is not semantically equal
+// to expected. Object key order and whitespace are ignored. expected may be a string
+// or []byte holding JSON, or any value that marshals to JSON.
+func (r *Response) ExpectJSONEqual(expected any) *Response {
+	r.t.Helper()
+	want, err := normalizeJSON(expected)
+	if err != nil {
+		r.t.Errorf("invalid expected JSON: %v", err)
+		return r
+	}
+	got, err := decodeJSON(r.Body)
+	if err != nil {
+		r.t.Errorf("%s: body is not valid JSON: %v\nbody: %s", r.describe(), err, r.quoteBody())
+		return r
+	}
+	if !jsonEqual(want, got) {
+		r.t.Errorf("%s: JSON body mismatch:\n%s", r.describe(), diff.Unified("expected", "actual", indentJSON(want), indentJSON(got)))
+	}
+	return r
+}
+
+// ExpectJSONPath reports an error if the value at path, e.g. `$.items[0].id`, is
+// missing or not equal to expected once both are compared as JSON.
+func (r *Response) ExpectJSONPath(path string, expected any) *Response {
+	r.t.Helper()
+	doc, err := decodeJSON(r.Body)
+	if err != nil {
+		r.t.Errorf("%s: body is not valid JSON: %v\nbody: %s", r.describe(), err, r.quoteBody())
+		return r
+	}
+	got, err := evalJSONPath(doc, path)
+	if err != nil {
+		r.t.Errorf("%s: JSON path %s: %v", r.describe(), path, err)
+		return r
+	}
+	want, err := toJSONValue(expected)
+	if err != nil {
+		r.t.Errorf("invalid expected value for %s: %v", path, err)
+		return r
+	}
+	if !jsonEqual(want, got) {
+		r.t.Errorf("%s: JSON path %s: expected %s, got %s", r.describe(), path, compactJSON(want), compactJSON(got))
+	}
+	return r
+}
+
+// describe identifies the request in failure messages.
+func (r *Response) describe() string {
+	if r.Raw == nil || r.Raw.Request == nil {
+		return "response"
+	}
+	return r.Raw.Request.Method + " " + r.Raw.Request.URL.RequestURI()
+}
+
+func (r *Response) quoteBody() string {
+	if len(r.Body) > maxBodyInMessage {
+		return fmt.Sprintf("%q... (%d bytes)", r.Body[:maxBodyInMessage], len(r.Body))
+	}
+	return fmt.Sprintf("%q", r.Body)
+}
+
+// normalizeJSON decodes raw JSON given as string or []byte, or round-trips other values.
+func normalizeJSON(v any) (any, error) {
+	var raw []byte
+	switch v := v.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return toJSONValue(v)
+	}
+	return decodeJSON(raw)
+}
+
+// toJSONValue converts v to its generic JSON representation, as decoded by decodeJSON.
+func toJSONValue(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(raw)
+}
+
+// decodeJSON is json.Unmarshal into any, except that numbers decode as json.Number
+// so that integers beyond float64 precision, such as int64 ids, stay exact.
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after top-level value")
+	}
+	return out, nil
+}
+
+// jsonEqual reports whether two values decoded by decodeJSON are equal. Numbers are
+// compared by value, exactly, so 1, 1.0 and 1e0 are equal but two ids that differ
+// beyond float64 precision are not.
+func jsonEqual(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okA := new(big.Rat).SetString(a.String())
+		y, okB := new(big.Rat).SetString(b.String())
+		if !okA || !okB {
+			return a == b
+		}
+		return x.Cmp(y) == 0
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// indentJSON pretty-prints v; map keys are sorted by encoding/json.
+func indentJSON(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	return buf.String()
+}
+
+func compactJSON(v any) string {
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
+
+// MatchSnapshot compares the body with the snapshot of the current test. JSON bodies
+// are compared as indented JSON with sorted keys, other bodies as text.
+func (r *Response) MatchSnapshot(opts ...snapshot.Option) *Response {
+	r.t.Helper()
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		snapshot.Match(r.t, json.RawMessage(r.Body), opts...)
+	} else {
+		snapshot.Match(r.t, r.Body, opts...)
+	}
+	return r
+}
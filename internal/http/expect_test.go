@@ -0,0 +1,123 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// errorfTB records Errorf calls instead of failing the test.
+type errorfTB struct {
+	testing.TB
+	errors []string
+}
+
+func (m *errorfTB) Errorf(format string, args ...any) {
+	m.errors = append(m.errors, fmt.Sprintf(format, args...))
+}
+
+func TestResponse_Expect(t *testing.T) {
+	srv, cleanup := NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("X-Version", "2")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"name":"widget","items":[{"id":5,"tags":["a"]},{"id":7}],"odd key":true}`))
+	}))
+	defer func() { _ = cleanup() }()
+
+	t.Run("Passing", func(t *testing.T) {
+		mockT := &errorfTB{TB: t}
+		NewClient(mockT, srv.BaseURL(), srv.Client()).Get("/items").Do().
+			ExpectStatus(http.StatusCreated).
+			ExpectHeader("X-Version", "2").
+			ExpectContentType("application/json").
+			ExpectContentType("application/json; charset=utf-8").
+			ExpectJSONEqual(`{"odd key":true,"items":[{"tags":["a"],"id":5},{"id":7}],"name":"widget"}`).
+			ExpectJSONPath("$.items[0].id", 5).
+			ExpectJSONPath("$.items[0].tags", []string{"a"}).
+			ExpectJSONPath(`$["odd key"]`, true).
+			ExpectJSONPath("$.name", "widget")
+		if len(mockT.errors) != 0 {
+			t.Fatalf("expected no errors, got %q", mockT.errors)
+		}
+	})
+
+	t.Run("ReportsEveryMismatch", func(t *testing.T) {
+		mockT := &errorfTB{TB: t}
+		NewClient(mockT, srv.BaseURL(), srv.Client()).Get("/items").Do().
+			ExpectStatus(http.StatusOK).
+			ExpectHeader("X-Version", "3").
+			ExpectHeader("X-Missing", "1").
+			ExpectContentType("text/plain").
+			ExpectJSONEqual(map[string]any{"name": "gadget", "items": []any{}, "odd key": true}).
+			ExpectJSONPath("$.items[1].id", 8).
+			ExpectJSONPath("$.items[2].id", 8).
+			ExpectJSONPath("$.name.first", "x")
+
+		want := []string{
+			"expected status 200, got 201",
+			`expected header X-Version: "3", got ["2"]`,
+			`expected header X-Missing: "1", header missing`,
+			`expected Content-Type "text/plain", got "application/json; charset=utf-8"`,
+			`-  "name": "gadget"`,
+			"$.items[1].id: expected 8, got 7",
+			"$.items has 2 element(s), index 2 out of range",
+			"$.name is a string, not an object",
+		}
+		if len(mockT.errors) != len(want) {
+			t.Fatalf("expected %d errors, got %d: %q", len(want), len(mockT.errors), mockT.errors)
+		}
+		for i, w := range want {
+			if !strings.Contains(mockT.errors[i], w) {
+				t.Errorf("error %d: expected to contain %q, got %q", i, w, mockT.errors[i])
+			}
+			if !strings.HasPrefix(mockT.errors[i], "GET /items: ") {
+				t.Errorf("error %d: expected request in message, got %q", i, mockT.errors[i])
+			}
+		}
+	})
+}
+
+func TestResponse_ExpectJSON_LargeIntegers(t *testing.T) {
+	srv, cleanup := NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":9007199254740993,"ratio":1.0}`))
+	}))
+	defer func() { _ = cleanup() }()
+
+	mockT := &errorfTB{TB: t}
+	resp := NewClient(mockT, srv.BaseURL(), srv.Client()).Get("/").Do()
+	resp.ExpectJSONEqual(`{"id":9007199254740993,"ratio":1}`).
+		ExpectJSONPath("$.id", int64(9007199254740993)).
+		ExpectJSONPath("$.ratio", 1)
+	if len(mockT.errors) != 0 {
+		t.Fatalf("expected exact and equivalent numbers to match, got %q", mockT.errors)
+	}
+
+	// Both ids are the same float64
+	resp.ExpectJSONEqual(`{"id":9007199254740992,"ratio":1}`).
+		ExpectJSONPath("$.id", int64(9007199254740992))
+	if len(mockT.errors) != 2 {
+		t.Errorf("expected ids differing beyond float64 precision to mismatch, got %q", mockT.errors)
+	}
+}
+
+func TestEvalJSONPath(t *testing.T) {
+	doc := map[string]any{"a": []any{map[string]any{"b": "c"}}}
+	for path, want := range map[string]string{
+		"a":       "path must start with $",
+		"$.a[x]":  `invalid index "x"`,
+		"$.a[0":   "unterminated index",
+		`$["a`:    "unterminated key",
+		"$.a[0]!": `unexpected "!"`,
+	} {
+		if _, err := evalJSONPath(doc, path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected error containing %q, got %v", path, want, err)
+		}
+	}
+	got, err := evalJSONPath(doc, "$.a[0].b")
+	if err != nil || got != "c" {
+		t.Errorf("expected c, got %v (%v)", got, err)
+	}
+}
@@ -0,0 +1,102 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// inProcessURL is the base URL of in-process servers; .test is reserved for testing.
+const inProcessURL = "http://in-process.test"
+
+// inProcessRemoteAddr is the client address handlers see in in-process mode,
+// the same one httptest.NewRequest uses.
+const inProcessRemoteAddr = "192.0.2.1:1234"
+
+func (s *Server) startInProcess(handler http.Handler) func() error {
+	s.inProcess = true
+	s.baseURL = inProcessURL
+	s.client = &http.Client{
+		Transport: &trackingTransport{base: &handlerTransport{handler: handler, server: s}, server: s},
+	}
+	return func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.activeAtEnd = s.activeConnsLocked()
+		s.closed = true
+		return nil
+	}
+}
+
+// handlerTransport is an http.RoundTripper that serves requests by calling handler
+// with an httptest.ResponseRecorder.
+type handlerTransport struct {
+	handler http.Handler
+	server  *Server
+}
+
+// RoundTrip closes the request body once the handler returns or the request is
+// cancelled, as the http.RoundTripper contract requires.
+func (t *handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	closeBody := sync.OnceFunc(func() {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+	})
+
+	t.server.mu.Lock()
+	closed := t.server.closed
+	t.server.mu.Unlock()
+	if closed {
+		closeBody()
+		return nil, fmt.Errorf("in-process server is closed")
+	}
+
+	in := req.Clone(req.Context())
+	in.RequestURI = req.URL.RequestURI()
+	in.RemoteAddr = inProcessRemoteAddr
+	in.Proto, in.ProtoMajor, in.ProtoMinor = "HTTP/1.1", 1, 1
+	if in.Body == nil {
+		in.Body = http.NoBody
+	}
+	if in.Host == "" {
+		in.Host = req.URL.Host
+	}
+
+	t.server.mu.Lock()
+	t.server.inFlight[in] = "in-process " + req.Method + " " + in.RequestURI
+	t.server.mu.Unlock()
+
+	rec := httptest.NewRecorder()
+	done := make(chan any, 1)
+	go func() {
+		defer func() {
+			t.server.mu.Lock()
+			delete(t.server.inFlight, in)
+			t.server.mu.Unlock()
+			closeBody()
+			done <- recover()
+		}()
+		t.handler.ServeHTTP(rec, in)
+	}()
+
+	select {
+	case <-req.Context().Done():
+		closeBody()
+		return nil, req.Context().Err()
+	case p := <-done:
+		if p != nil {
+			// A real server drops the connection when a handler panics
+			return nil, fmt.Errorf("%s %s: handler panicked: %v", req.Method, in.RequestURI, p)
+		}
+	}
+
+	resp := rec.Result()
+	resp.Request = req
+	if req.Method == http.MethodHead {
+		resp.Body = http.NoBody
+	}
+	return resp, nil
+}
@@ -0,0 +1,112 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// closeTrackingBody records whether the transport closed the request body.
+type closeTrackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *closeTrackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestServer_InProcess(t *testing.T) {
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", echoHandler)
+	mux.HandleFunc("/panic", func(http.ResponseWriter, *http.Request) { panic("boom") })
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	srv, cleanup := NewServer(t, mux, InProcess())
+	if !srv.InProcess() || srv.BaseURL() != inProcessURL {
+		t.Fatalf("expected in-process server, got %s", srv.BaseURL())
+	}
+	c := NewClient(t, srv.BaseURL(), srv.Client())
+
+	t.Run("Request", func(t *testing.T) {
+		var got echo
+		c.Post("/echo").Query("a", "1").Header("X-Request-Id", "42").Body([]byte("hi"), "text/plain").Do().
+			ExpectStatus(http.StatusOK).
+			ExpectContentType("application/json").
+			DecodeJSON(&got)
+		if got.Method != http.MethodPost || got.Query.Get("a") != "1" || got.Header["X-Request-Id"] != "42" || got.Body != "hi" {
+			t.Errorf("unexpected request seen by handler: %+v", got)
+		}
+		if head := c.Head("/echo").Do(); len(head.Body) != 0 {
+			t.Errorf("expected HEAD response without body, got %q", head.Body)
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		_, err := srv.Client().Get(srv.BaseURL() + "/panic")
+		if err == nil || !strings.Contains(err.Error(), "handler panicked: boom") {
+			t.Errorf("expected panic to surface as transport error, got %v", err)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		mockT := &mockTB{TB: t}
+		NewClient(mockT, srv.BaseURL(), srv.Client()).Get("/slow").Timeout(10 * time.Millisecond).Do()
+		if !mockT.failed {
+			t.Error("expected timed out request to fail")
+		}
+	})
+
+	t.Run("BodyClosed", func(t *testing.T) {
+		for _, path := range []string{"/echo", "/panic"} {
+			body := &closeTrackingBody{Reader: strings.NewReader("hi")}
+			req, err := http.NewRequest(http.MethodPost, srv.BaseURL()+path, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp, err := srv.Client().Do(req); err == nil {
+				_ = resp.Body.Close()
+			}
+			if !body.closed {
+				t.Errorf("%s: expected request body to be closed", path)
+			}
+		}
+	})
+
+	t.Run("InFlight", func(t *testing.T) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			resp, err := srv.Client().Get(srv.BaseURL() + "/slow")
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+		deadline := time.Now().Add(time.Second)
+		for len(srv.ActiveConns()) == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		_ = cleanup()
+		if active := srv.ActiveConns(); len(active) != 1 || active[0] != "in-process GET /slow" {
+			t.Errorf("expected in-flight request to be reported, got %v", active)
+		}
+		close(release)
+		<-done
+		body := &closeTrackingBody{Reader: strings.NewReader("hi")}
+		if _, err := srv.Client().Post(srv.BaseURL()+"/echo", "text/plain", body); err == nil {
+			t.Error("expected requests to fail after cleanup")
+		}
+		if !body.closed {
+			t.Error("expected request body to be closed after a failed request")
+		}
+	})
+}
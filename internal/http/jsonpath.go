@@ -0,0 +1,93 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// evalJSONPath resolves a simple JSONPath expression such as `$.items[0].id` or
+// `$["a key"][2]` against a decoded JSON document.
+func evalJSONPath(doc any, path string) (any, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, fmt.Errorf("path must start with $")
+	}
+	cur := doc
+	walked := "$"
+	for rest != "" {
+		var step any
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			step, rest = rest[1:end+1], rest[end+1:]
+		case strings.HasPrefix(rest, `["`):
+			end := strings.Index(rest, `"]`)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated key in %s", path)
+			}
+			step, rest = rest[2:end], rest[end+2:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index in %s", path)
+			}
+			idx, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q in %s", rest[1:end], path)
+			}
+			step, rest = idx, rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in %s", rest, path)
+		}
+
+		switch s := step.(type) {
+		case string:
+			obj, ok := cur.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s is %s, not an object", walked, jsonKind(cur))
+			}
+			val, ok := obj[s]
+			if !ok {
+				return nil, fmt.Errorf("%s has no key %q", walked, s)
+			}
+			cur = val
+			walked += "." + s
+		case int:
+			arr, ok := cur.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s is %s, not an array", walked, jsonKind(cur))
+			}
+			if s < 0 || s >= len(arr) {
+				return nil, fmt.Errorf("%s has %d element(s), index %d out of range", walked, len(arr), s)
+			}
+			cur = arr[s]
+			walked += "[" + strconv.Itoa(s) + "]"
+		}
+	}
+	return cur, nil
+}
+
+func jsonKind(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case json.Number, float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,430 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+)
+
+// Mock is a Server whose responses are programmed with expectations. Requests that
+// match no expectation, exceed their call count or arrive out of order are recorded
+// and reported by Verify, together with expectations that were not met.
+type Mock struct {
+	*Server
+
+	mu           sync.Mutex
+	expectations []*Expectation
+	requests     []MockRequest
+	errs         []error
+}
+
+// MockRequest is a request received by a Mock. It is also the data of response templates.
+type MockRequest struct {
+	Method string
+	Path   string
+	Params map[string]string // values of {name} segments in the matched path pattern
+	Query  url.Values
+	Header http.Header
+	Body   string
+	JSON   any // decoded body with numbers as json.Number, nil if it is not JSON
+}
+
+// NewMock starts a mock server. The returned cleanup closes it.
+func NewMock(t testing.TB, opts ...ServerOption) (*Mock, func() error) {
+	m := &Mock{}
+	server, cleanup := NewServer(t, http.HandlerFunc(m.serve), opts...)
+	m.Server = server
+	return m, cleanup
+}
+
+// Expect declares an expected request. pattern is a path whose segments may be
+// {name} placeholders matching one segment, or a final {name...} matching the rest.
+// By default the expectation must be called at least once and answers 200 with an
+// empty body.
+func (m *Mock) Expect(method, pattern string) *Expectation {
+	e := &Expectation{
+		mock:     m,
+		method:   strings.ToUpper(method),
+		pattern:  strings.Split(strings.Trim(pattern, "/"), "/"),
+		source:   pattern,
+		min:      1,
+		max:      -1,
+		status:   http.StatusOK,
+		respHead: make(http.Header),
+	}
+	m.mu.Lock()
+	m.expectations = append(m.expectations, e)
+	m.mu.Unlock()
+	return e
+}
+
+// InOrder requires each expectation to be called only after the previous one was met.
+func (m *Mock) InOrder(expectations ...*Expectation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := 1; i < len(expectations); i++ {
+		expectations[i].after = expectations[i-1]
+	}
+}
+
+// Requests returns the requests received so far.
+func (m *Mock) Requests() []MockRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockRequest(nil), m.requests...)
+}
+
+// Verify returns an error describing unexpected calls and unmet expectations, or nil.
+func (m *Mock) Verify() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	errs := append([]error(nil), m.errs...)
+	for _, e := range m.expectations {
+		if e.calls < e.min {
+			errs = append(errs, fmt.Errorf("%s: called %d time(s), expected %s", e, e.calls, e.countText()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Mock) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req := MockRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   string(body),
+	}
+	req.JSON, _ = decodeJSON(body)
+	call := r.Method + " " + r.URL.RequestURI()
+
+	m.mu.Lock()
+	var (
+		match     *Expectation
+		exhausted *Expectation
+		reasons   []string
+	)
+	for _, e := range m.expectations {
+		params, reason := e.match(&req)
+		if params == nil {
+			if reason != "" {
+				reasons = append(reasons, fmt.Sprintf("%s: %s", e, reason))
+			}
+			continue
+		}
+		if e.max >= 0 && e.calls >= e.max {
+			if exhausted == nil {
+				exhausted = e
+			}
+			continue
+		}
+		req.Params = params
+		match = e
+		break
+	}
+	m.requests = append(m.requests, req)
+
+	if match == nil {
+		var err error
+		switch {
+		case exhausted != nil:
+			err = fmt.Errorf("%s: unexpected call %d to %s, expected %s", exhausted, exhausted.calls+1, call, exhausted.countText())
+		case len(reasons) > 0:
+			err = fmt.Errorf("unexpected request %s:\n  %s", call, strings.Join(reasons, "\n  "))
+		default:
+			err = fmt.Errorf("unexpected request %s", call)
+		}
+		m.errs = append(m.errs, err)
+		m.mu.Unlock()
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	match.calls++
+	if prev := match.after; prev != nil && prev.calls < max(prev.min, 1) {
+		m.errs = append(m.errs, fmt.Errorf("%s: called before %s", match, prev))
+	}
+	respond := match.respond
+	header, status, respBody, tmpl := match.respHead.Clone(), match.status, match.body, match.tmpl
+	m.mu.Unlock()
+
+	if respond != nil {
+		respond(w, r)
+		return
+	}
+	if tmpl != nil {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, req); err != nil {
+			m.mu.Lock()
+			m.errs = append(m.errs, fmt.Errorf("%s: response template: %w", match, err))
+			m.mu.Unlock()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		respBody = buf.Bytes()
+	}
+	for key, values := range header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(respBody)
+}
+
+// Expectation is a request a Mock expects, with the response it answers. Its
+// methods return the Expectation so calls can be chained.
+type Expectation struct {
+	mock    *Mock
+	method  string
+	pattern []string
+	source  string
+
+	header   http.Header
+	query    url.Values
+	json     any
+	hasJSON  bool
+	matchers []func(MockRequest) bool
+
+	min, max int // max < 0 means unlimited
+	calls    int
+	after    *Expectation
+
+	status   int
+	respHead http.Header
+	body     []byte
+	tmpl     *template.Template
+	respond  http.HandlerFunc
+}
+
+// String describes the expectation, e.g. "GET /users/{id}".
+func (e *Expectation) String() string {
+	return e.method + " " + e.source
+}
+
+func (e *Expectation) update(fn func()) *Expectation {
+	e.mock.mu.Lock()
+	defer e.mock.mu.Unlock()
+	fn()
+	return e
+}
+
+// WithHeader requires the request header key to have value.
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	return e.update(func() {
+		if e.header == nil {
+			e.header = make(http.Header)
+		}
+		e.header.Add(key, value)
+	})
+}
+
+// WithQuery requires the query parameter key to have value.
+func (e *Expectation) WithQuery(key, value string) *Expectation {
+	return e.update(func() {
+		if e.query == nil {
+			e.query = make(url.Values)
+		}
+		e.query.Add(key, value)
+	})
+}
+
+// WithJSON requires the body to be JSON semantically equal to expected, which may
+// be a JSON string or []byte, or any value that marshals to JSON.
+func (e *Expectation) WithJSON(expected any) *Expectation {
+	want, err := normalizeJSON(expected)
+	return e.update(func() {
+		if err != nil {
+			e.mock.errs = append(e.mock.errs, fmt.Errorf("%s: invalid expected JSON: %w", e, err))
+		}
+		e.json, e.hasJSON = want, true
+	})
+}
+
+// Matching adds a custom condition on the request.
+func (e *Expectation) Matching(fn func(MockRequest) bool) *Expectation {
+	return e.update(func() { e.matchers = append(e.matchers, fn) })
+}
+
+// Times requires exactly n calls. Further matching calls are reported as unexpected.
+func (e *Expectation) Times(n int) *Expectation {
+	return e.update(func() { e.min, e.max = n, n })
+}
+
+// Once is Times(1).
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+// Never reports every matching call as unexpected.
+func (e *Expectation) Never() *Expectation {
+	return e.Times(0)
+}
+
+// AtLeast requires n or more calls.
+func (e *Expectation) AtLeast(n int) *Expectation {
+	return e.update(func() { e.min, e.max = n, -1 })
+}
+
+// AnyTimes allows any number of calls, including none.
+func (e *Expectation) AnyTimes() *Expectation {
+	return e.AtLeast(0)
+}
+
+// After requires prev to have been met before this expectation is called.
+func (e *Expectation) After(prev *Expectation) *Expectation {
+	return e.update(func() { e.after = prev })
+}
+
+// Calls returns how many requests the expectation has answered.
+func (e *Expectation) Calls() int {
+	e.mock.mu.Lock()
+	defer e.mock.mu.Unlock()
+	return e.calls
+}
+
+// Respond sets the response status and body. Strings and byte slices are sent as
+// is; other values are encoded as JSON with a matching Content-Type.
+func (e *Expectation) Respond(status int, body any) *Expectation {
+	var raw []byte
+	contentType := ""
+	switch body := body.(type) {
+	case nil:
+	case string:
+		raw = []byte(body)
+	case []byte:
+		raw = body
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			e.mock.mu.Lock()
+			e.mock.errs = append(e.mock.errs, fmt.Errorf("%s: failed to marshal response: %w", e, err))
+			e.mock.mu.Unlock()
+		}
+		raw, contentType = data, "application/json"
+	}
+	return e.update(func() {
+		e.status, e.body, e.tmpl, e.respond = status, raw, nil, nil
+		if contentType != "" && e.respHead.Get("Content-Type") == "" {
+			e.respHead.Set("Content-Type", contentType)
+		}
+	})
+}
+
+// RespondTemplate sets the response status and a text/template body executed with
+// the MockRequest, e.g. `{"id": "{{.Params.id}}", "name": "{{.JSON.name}}"}`.
+// An invalid template is reported by Verify.
+func (e *Expectation) RespondTemplate(status int, body string) *Expectation {
+	tmpl, err := template.New(e.String()).Option("missingkey=error").Parse(body)
+	return e.update(func() {
+		if err != nil {
+			e.mock.errs = append(e.mock.errs, fmt.Errorf("%s: invalid response template: %w", e, err))
+		}
+		e.status, e.body, e.tmpl, e.respond = status, nil, tmpl, nil
+	})
+}
+
+// RespondHeader adds a header to the response.
+func (e *Expectation) RespondHeader(key, value string) *Expectation {
+	return e.update(func() { e.respHead.Add(key, value) })
+}
+
+// RespondWith answers matching requests with handler. The request body has already
+// been read; use MockRequest data through Mock.Requests if needed.
+func (e *Expectation) RespondWith(handler http.HandlerFunc) *Expectation {
+	return e.update(func() { e.respond = handler })
+}
+
+// match returns the path parameters if req matches, or nil and the reason it does
+// not. The reason is empty when method or path differ, since such expectations are
+// not relevant to explain an unexpected request.
+func (e *Expectation) match(req *MockRequest) (map[string]string, string) {
+	if e.method != req.Method {
+		return nil, ""
+	}
+	params := matchPath(e.pattern, strings.Split(strings.Trim(req.Path, "/"), "/"))
+	if params == nil {
+		return nil, ""
+	}
+	for key, values := range e.header {
+		got := req.Header.Values(key)
+		for _, want := range values {
+			if !slices.Contains(got, want) {
+				return nil, fmt.Sprintf("header %s: want %q, got %q", key, want, got)
+			}
+		}
+	}
+	for key, values := range e.query {
+		got := req.Query[key]
+		for _, want := range values {
+			if !slices.Contains(got, want) {
+				return nil, fmt.Sprintf("query %s: want %q, got %q", key, want, got)
+			}
+		}
+	}
+	if e.hasJSON && !jsonEqual(e.json, req.JSON) {
+		return nil, fmt.Sprintf("JSON body: want %s, got %s", compactJSON(e.json), req.Body)
+	}
+	for _, fn := range e.matchers {
+		if !fn(*req) {
+			return nil, "custom matcher rejected the request"
+		}
+	}
+	return params, ""
+}
+
+// matchPath matches path segments against pattern segments and returns the
+// placeholder values, or nil if they do not match.
+func matchPath(pattern, path []string) map[string]string {
+	params := make(map[string]string)
+	for i, seg := range pattern {
+		if name, ok := strings.CutSuffix(strings.TrimPrefix(seg, "{"), "...}"); ok && strings.HasPrefix(seg, "{") && i == len(pattern)-1 {
+			if i < len(path) {
+				params[name] = strings.Join(path[i:], "/")
+			} else {
+				params[name] = ""
+			}
+			return params
+		}
+		if i >= len(path) {
+			return nil
+		}
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if path[i] == "" {
+				return nil
+			}
+			params[seg[1:len(seg)-1]] = path[i]
+			continue
+		}
+		if seg != path[i] {
+			return nil
+		}
+	}
+	if len(path) != len(pattern) {
+		return nil
+	}
+	return params
+}
+
+func (e *Expectation) countText() string {
+	switch {
+	case e.max == 0:
+		return "never"
+	case e.min == e.max:
+		return fmt.Sprintf("exactly %d time(s)", e.min)
+	case e.min == 0:
+		return "any number of times"
+	case e.min == 1:
+		return "at least once"
+	default:
+		return fmt.Sprintf("at least %d times", e.min)
+	}
+}
@@ -0,0 +1,133 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMock(t *testing.T) {
+	t.Run("Met", func(t *testing.T) {
+		m, cleanup := NewMock(t)
+		defer func() { _ = cleanup() }()
+		c := NewClient(t, m.BaseURL(), m.Client())
+
+		create := m.Expect("POST", "/users").
+			WithHeader("Authorization", "Bearer t0k3n").
+			WithJSON(`{"name": "alice", "tags": ["a"]}`).
+			RespondTemplate(http.StatusCreated, `{"id": 7, "name": "{{.JSON.name}}"}`).
+			RespondHeader("Content-Type", "application/json").
+			Once()
+		get := m.Expect("GET", "/users/{id}").
+			WithQuery("expand", "groups").
+			Respond(http.StatusOK, map[string]any{"id": 7}).
+			Times(2)
+		files := m.Expect("GET", "/files/{path...}").
+			RespondWith(func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(r.URL.Path)) }).
+			AnyTimes()
+		m.Expect("DELETE", "/users/{id}").Never()
+		m.InOrder(create, get)
+
+		c.Post("/users").BearerToken("t0k3n").JSON(map[string]any{"tags": []string{"a"}, "name": "alice"}).Do().
+			ExpectStatus(http.StatusCreated).
+			ExpectJSONEqual(`{"id": 7, "name": "alice"}`)
+		for range 2 {
+			c.Get("/users/7").Query("expand", "groups").Do().
+				ExpectStatus(http.StatusOK).
+				ExpectContentType("application/json").
+				ExpectJSONPath("$.id", 7)
+		}
+		c.Get("/files/a/b.txt").Do().ExpectStatus(http.StatusOK)
+
+		if err := m.Verify(); err != nil {
+			t.Fatalf("expected expectations to be met, got %v", err)
+		}
+		if create.Calls() != 1 || get.Calls() != 2 || files.Calls() != 1 || len(m.Requests()) != 4 {
+			t.Errorf("unexpected call counts %d %d %d %d", create.Calls(), get.Calls(), files.Calls(), len(m.Requests()))
+		}
+		if got := m.Requests()[1].Query.Get("expand"); got != "groups" {
+			t.Errorf("expected recorded query, got %q", got)
+		}
+	})
+
+	t.Run("Violations", func(t *testing.T) {
+		m, cleanup := NewMock(t)
+		defer func() { _ = cleanup() }()
+		c := NewClient(t, m.BaseURL(), m.Client())
+
+		login := m.Expect("POST", "/login").Respond(http.StatusNoContent, nil)
+		list := m.Expect("GET", "/items").Respond(http.StatusOK, "[]").Once()
+		m.Expect("DELETE", "/items/{id}").Never()
+		m.Expect("PUT", "/items/{id}").WithJSON(map[string]int{"n": 1})
+		m.Expect("GET", "/unused").Times(2)
+		list.After(login)
+
+		c.Get("/items").Do().ExpectStatus(http.StatusOK)
+		c.Get("/items").Do().ExpectStatus(http.StatusNotImplemented)
+		c.Delete("/items/1").Do().ExpectStatus(http.StatusNotImplemented)
+		c.Put("/items/1").JSON(map[string]int{"n": 2}).Do().ExpectStatus(http.StatusNotImplemented)
+		c.Get("/nothing").Do().ExpectStatus(http.StatusNotImplemented)
+
+		err := m.Verify()
+		if err == nil {
+			t.Fatal("expected violations")
+		}
+		want := []string{
+			"GET /items: called before POST /login",
+			"GET /items: unexpected call 2 to GET /items, expected exactly 1 time(s)",
+			"DELETE /items/{id}: unexpected call 1 to DELETE /items/1, expected never",
+			"unexpected request PUT /items/1:\n  PUT /items/{id}: JSON body: want {\"n\":1}, got {\"n\":2}",
+			"unexpected request GET /nothing",
+			"POST /login: called 0 time(s), expected at least once",
+			"PUT /items/{id}: called 0 time(s), expected at least once",
+			"GET /unused: called 0 time(s), expected exactly 2 time(s)",
+		}
+		if got := err.Error(); got != strings.Join(want, "\n") {
+			t.Errorf("unexpected report:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+		}
+	})
+
+	t.Run("LargeIntegers", func(t *testing.T) {
+		m, cleanup := NewMock(t)
+		defer func() { _ = cleanup() }()
+		c := NewClient(t, m.BaseURL(), m.Client())
+		m.Expect("POST", "/orders").WithJSON(`{"user_id": 9007199254740993}`).Respond(http.StatusCreated, nil)
+
+		// Equal to the expected id once both are rounded to float64
+		c.Post("/orders").Body([]byte(`{"user_id": 9007199254740992}`), "application/json").Do().ExpectStatus(http.StatusNotImplemented)
+		c.Post("/orders").Body([]byte(`{"user_id": 9007199254740993.0}`), "application/json").Do().ExpectStatus(http.StatusCreated)
+
+		if got := m.Requests()[0].JSON.(map[string]any)["user_id"]; got != json.Number("9007199254740992") {
+			t.Errorf("expected the decoded body to keep the exact number, got %v", got)
+		}
+	})
+}
+
+func TestMatchPath(t *testing.T) {
+	split := func(s string) []string { return strings.Split(strings.Trim(s, "/"), "/") }
+	for _, tc := range []struct {
+		pattern, path string
+		want          map[string]string
+	}{
+		{"/", "/", map[string]string{}},
+		{"/a/{id}", "/a/1", map[string]string{"id": "1"}},
+		{"/a/{id}", "/a/", nil},
+		{"/a/{id}", "/a/1/b", nil},
+		{"/a/{rest...}", "/a/1/b", map[string]string{"rest": "1/b"}},
+		{"/a/{rest...}", "/a", map[string]string{"rest": ""}},
+		{"/a/b", "/a/c", nil},
+	} {
+		got := matchPath(split(tc.pattern), split(tc.path))
+		if (got == nil) != (tc.want == nil) || len(got) != len(tc.want) {
+			t.Errorf("%s %s: expected %v, got %v", tc.pattern, tc.path, tc.want, got)
+			continue
+		}
+		for key, val := range tc.want {
+			if got[key] != val {
+				t.Errorf("%s %s: expected %v, got %v", tc.pattern, tc.path, tc.want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Client builds requests against a base URL and captures their responses.
+type Client struct {
+	t       testing.TB
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient creates a request builder for baseURL that sends requests with client
+// and reports failures to t.
+func NewClient(t testing.TB, baseURL string, client *http.Client) *Client {
+	return &Client{t: t, baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+// Get starts a GET request to path.
+func (c *Client) Get(path string) *Request { return c.Method(http.MethodGet, path) }
+
+// Head starts a HEAD request to path.
+func (c *Client) Head(path string) *Request { return c.Method(http.MethodHead, path) }
+
+// Post starts a POST request to path.
+func (c *Client) Post(path string) *Request { return c.Method(http.MethodPost, path) }
+
+// Put starts a PUT request to path.
+func (c *Client) Put(path string) *Request { return c.Method(http.MethodPut, path) }
+
+// Patch starts a PATCH request to path.
+func (c *Client) Patch(path string) *Request { return c.Method(http.MethodPatch, path) }
+
+// Delete starts a DELETE request to path.
+func (c *Client) Delete(path string) *Request { return c.Method(http.MethodDelete, path) }
+
+// Options starts an OPTIONS request to path.
+func (c *Client) Options(path string) *Request { return c.Method(http.MethodOptions, path) }
+
+// Method starts a request with an arbitrary method to path. Paths are relative to
+// the base URL unless they are absolute URLs.
+func (c *Client) Method(method, path string) *Request {
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		header: make(http.Header),
+		query:  make(url.Values),
+	}
+}
+
+// multipartFile is a file part of a multipart body.
+type multipartFile struct {
+	field, filename string
+	content         []byte
+}
+
+// formField is a name/value pair of a form body. Fields are kept in a slice so that
+// they are encoded in the order they were added.
+type formField struct {
+	name, value string
+}
+
+// Request is a request under construction. Its methods return the Request so calls
+// can be chained, ending with Do.
+type Request struct {
+	client *Client
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	cookie []*http.Cookie
+
+	body        []byte
+	contentType string
+	fields      []formField
+	files       []multipartFile
+	err         error
+
+	timeout time.Duration
+}
+
+// Header sets a request header, replacing existing values.
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Query adds a query parameter. Parameters already present in the path are kept.
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Cookie adds a cookie to the request.
+func (r *Request) Cookie(cookie *http.Cookie) *Request {
+	r.cookie = append(r.cookie, cookie)
+	return r
+}
+
+// BasicAuth sets the Authorization header to use HTTP basic authentication.
+func (r *Request) BasicAuth(username, password string) *Request {
+	req := http.Request{Header: make(http.Header)}
+	req.SetBasicAuth(username, password)
+	return r.Header("Authorization", req.Header.Get("Authorization"))
+}
+
+// BearerToken sets the Authorization header to a bearer token.
+func (r *Request) BearerToken(token string) *Request {
+	return r.Header("Authorization", "Bearer "+token)
+}
+
+// JSON sets a JSON-encoded body and the matching Content-Type.
+func (r *Request) JSON(value any) *Request {
+	data, err := json.Marshal(value)
+	if err != nil {
+		r.err = fmt.Errorf("failed to marshal JSON: %w", err)
+		return r
+	}
+	return r.Body(data, "application/json")
+}
+
+// Body sets a raw body with the given Content-Type.
+func (r *Request) Body(body []byte, contentType string) *Request {
+	r.body = body
+	r.contentType = contentType
+	r.fields, r.files = nil, nil
+	return r
+}
+
+// Form sets a URL-encoded form body.
+func (r *Request) Form(values url.Values) *Request {
+	return r.Body([]byte(values.Encode()), "application/x-www-form-urlencoded")
+}
+
+// MultipartField adds a field to a multipart/form-data body.
+func (r *Request) MultipartField(name, value string) *Request {
+	r.fields = append(r.fields, formField{name: name, value: value})
+	r.body = nil
+	return r
+}
+
+// MultipartFile adds a file to a multipart/form-data body.
+func (r *Request) MultipartFile(field, filename string, content []byte) *Request {
+	r.files = append(r.files, multipartFile{field: field, filename: filename, content: content})
+	r.body = nil
+	return r
+}
+
+// Timeout bounds the whole request, including reading the response body.
+func (r *Request) Timeout(d time.Duration) *Request {
+	r.timeout = d
+	return r
+}
+
+// Do sends the request and returns the response with its body fully read.
+// Transport errors and invalid requests fail the test.
+func (r *Request) Do() *Response {
+	t := r.client.t
+	t.Helper()
+
+	req, cancel, err := r.build()
+	if err != nil {
+		t.Fatalf("%s %s: %v", r.method, r.path, err)
+		return nil
+	}
+	defer cancel()
+
+	start := time.Now()
+	resp, err := r.client.client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", r.method, r.path, err)
+		return nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("%s %s: failed to read response body: %v", r.method, r.path, err)
+		return nil
+	}
+	return newResponse(r.client, resp, body, time.Since(start))
+}
+
+// build assembles the *http.Request. The returned cancel releases the timeout context.
+func (r *Request) build() (*http.Request, context.CancelFunc, error) {
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+
+	target, err := url.Parse(r.path)
+	if err == nil && !target.IsAbs() {
+		target, err = url.Parse(r.client.baseURL + r.path)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// Re-encoding sorts the query and normalizes it, so leave a query written into
+	// the path untouched unless parameters were added
+	if len(r.query) > 0 {
+		query := target.Query()
+		for key, values := range r.query {
+			query[key] = append(query[key], values...)
+		}
+		target.RawQuery = query.Encode()
+	}
+
+	body, contentType := r.body, r.contentType
+	if r.fields != nil || r.files != nil {
+		body, contentType, err = r.multipart()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if r.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+	}
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, target.String(), reader)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	req.Header = r.header.Clone()
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for _, c := range r.cookie {
+		req.AddCookie(c)
+	}
+	return req, cancel, nil
+}
+
+func (r *Request) multipart() ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, f := range r.fields {
+		if err := w.WriteField(f.name, f.value); err != nil {
+			return nil, "", err
+		}
+	}
+	for _, f := range r.files {
+		part, err := w.CreateFormFile(f.field, f.filename)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(f.content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
@@ -0,0 +1,230 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type echo struct {
+	Method      string            `json:"method"`
+	Path        string            `json:"path"`
+	Query       url.Values        `json:"query"`
+	Header      map[string]string `json:"header"`
+	Cookie      string            `json:"cookie"`
+	Body        string            `json:"body"`
+	FormFields  url.Values        `json:"form"`
+	FileContent string            `json:"file"`
+}
+
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	res := echo{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: map[string]string{
+			"Authorization": r.Header.Get("Authorization"),
+			"Content-Type":  r.Header.Get("Content-Type"),
+			"X-Request-Id":  r.Header.Get("X-Request-Id"),
+		},
+	}
+	if c, err := r.Cookie("session"); err == nil {
+		res.Cookie = c.Value
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		_ = r.ParseMultipartForm(1 << 20)
+		res.FormFields = url.Values(r.MultipartForm.Value)
+		if f, _, err := r.FormFile("upload"); err == nil {
+			data, _ := io.ReadAll(f)
+			res.FileContent = string(data)
+		}
+	} else {
+		data, _ := io.ReadAll(r.Body)
+		res.Body = string(data)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	http.SetCookie(w, &http.Cookie{Name: "seen", Value: "1"})
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func TestClient_Request(t *testing.T) {
+	srv, cleanup := NewServer(t, http.HandlerFunc(echoHandler))
+	defer func() { _ = cleanup() }()
+	c := NewClient(t, srv.BaseURL(), srv.Client())
+
+	t.Run("Methods", func(t *testing.T) {
+		for method, req := range map[string]*Request{
+			http.MethodGet:     c.Get("/"),
+			http.MethodPost:    c.Post("/"),
+			http.MethodPut:     c.Put("/"),
+			http.MethodPatch:   c.Patch("/"),
+			http.MethodDelete:  c.Delete("/"),
+			http.MethodOptions: c.Options("/"),
+			"PURGE":            c.Method("PURGE", "/"),
+		} {
+			var res echo
+			req.Do().DecodeJSON(&res)
+			if res.Method != method {
+				t.Errorf("expected %s, got %s", method, res.Method)
+			}
+		}
+		if resp := c.Head("/").Do(); resp.StatusCode != http.StatusOK || len(resp.Body) != 0 {
+			t.Errorf("unexpected HEAD response: %d %q", resp.StatusCode, resp.Body)
+		}
+	})
+
+	t.Run("HeadersQueryAuthCookies", func(t *testing.T) {
+		var res echo
+		resp := c.Put("/items/1?a=1").
+			Header("X-Request-Id", "abc").
+			Query("b", "2").
+			BearerToken("secret").
+			Cookie(&http.Cookie{Name: "session", Value: "s1"}).
+			JSON(map[string]int{"qty": 3}).
+			Do()
+		resp.DecodeJSON(&res)
+
+		if res.Path != "/items/1" || res.Query.Get("a") != "1" || res.Query.Get("b") != "2" {
+			t.Errorf("unexpected path/query: %s %v", res.Path, res.Query)
+		}
+		if res.Header["Authorization"] != "Bearer secret" || res.Header["X-Request-Id"] != "abc" {
+			t.Errorf("unexpected headers: %v", res.Header)
+		}
+		if res.Header["Content-Type"] != "application/json" || res.Body != `{"qty":3}` {
+			t.Errorf("unexpected JSON body: %s %q", res.Header["Content-Type"], res.Body)
+		}
+		if res.Cookie != "s1" {
+			t.Errorf("expected session cookie, got %q", res.Cookie)
+		}
+		if cookies := resp.Cookies(); len(cookies) != 1 || cookies[0].Name != "seen" {
+			t.Errorf("expected response cookie, got %v", cookies)
+		}
+	})
+
+	t.Run("BasicAuth", func(t *testing.T) {
+		var res echo
+		c.Get("/").BasicAuth("user", "pass").Do().DecodeJSON(&res)
+		if res.Header["Authorization"] != "Basic dXNlcjpwYXNz" {
+			t.Errorf("unexpected Authorization: %q", res.Header["Authorization"])
+		}
+	})
+
+	t.Run("Form", func(t *testing.T) {
+		var res echo
+		c.Post("/").Form(url.Values{"name": {"a b"}}).Do().DecodeJSON(&res)
+		if res.Header["Content-Type"] != "application/x-www-form-urlencoded" || res.Body != "name=a+b" {
+			t.Errorf("unexpected form body: %s %q", res.Header["Content-Type"], res.Body)
+		}
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		var res echo
+		c.Post("/").Body([]byte("<xml/>"), "application/xml").Do().DecodeJSON(&res)
+		if res.Header["Content-Type"] != "application/xml" || res.Body != "<xml/>" {
+			t.Errorf("unexpected raw body: %s %q", res.Header["Content-Type"], res.Body)
+		}
+	})
+
+	t.Run("Multipart", func(t *testing.T) {
+		var res echo
+		c.Post("/upload").
+			MultipartField("title", "report").
+			MultipartFile("upload", "report.csv", []byte("a,b\n1,2")).
+			Do().
+			DecodeJSON(&res)
+		if res.FormFields.Get("title") != "report" || res.FileContent != "a,b\n1,2" {
+			t.Errorf("unexpected multipart body: %v %q", res.FormFields, res.FileContent)
+		}
+	})
+
+	t.Run("BodyReadableAfterClose", func(t *testing.T) {
+		resp := c.Get("/").Do()
+		if !strings.Contains(resp.Text(), `"method":"GET"`) {
+			t.Errorf("expected captured body, got %q", resp.Text())
+		}
+		if open := srv.OpenBodies(); len(open) != 0 {
+			t.Errorf("expected response body to be closed, got %v", open)
+		}
+	})
+}
+
+func TestClient_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	srv, cleanup := NewServer(t, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer func() { _ = cleanup() }()
+	defer close(release)
+
+	mockT := &mockTB{TB: t}
+	NewClient(mockT, srv.BaseURL(), srv.Client()).Get("/slow").Timeout(10 * time.Millisecond).Do()
+	if !mockT.failed {
+		t.Error("expected request to fail after its timeout")
+	}
+}
+
+func TestRequest_Build(t *testing.T) {
+	c := NewClient(t, "http://example.test", nil)
+
+	t.Run("PathQueryKept", func(t *testing.T) {
+		for path, want := range map[string]string{
+			"/x?flag":    "flag",
+			"/x?b=2&a=1": "b=2&a=1",
+		} {
+			req, cancel, err := c.Get(path).build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cancel()
+			if req.URL.RawQuery != want {
+				t.Errorf("%s: expected query %q, got %q", path, want, req.URL.RawQuery)
+			}
+		}
+
+		req, cancel, err := c.Get("/x?b=2").Query("a", "1").build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		if req.URL.RawQuery != "a=1&b=2" {
+			t.Errorf("expected merged query, got %q", req.URL.RawQuery)
+		}
+	})
+
+	t.Run("MultipartFieldOrder", func(t *testing.T) {
+		req, cancel, err := c.Post("/").
+			MultipartField("zeta", "1").
+			MultipartField("alpha", "2").
+			MultipartField("zeta", "3").
+			MultipartField("mid", "4").
+			build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		reader, err := req.MultipartReader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, _ := io.ReadAll(part)
+			got = append(got, part.FormName()+"="+string(value))
+		}
+		if want := "zeta=1 alpha=2 zeta=3 mid=4"; strings.Join(got, " ") != want {
+			t.Errorf("expected parts in insertion order %q, got %q", want, got)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Response is a captured HTTP response whose body has been fully read, so it can be
+// inspected after the connection is closed.
+type Response struct {
+	t      testing.TB
+	client *Client
+
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration
+
+	// Raw is the original response; its Body is already closed.
+	Raw *http.Response
+}
+
+func newResponse(c *Client, resp *http.Response, body []byte, duration time.Duration) *Response {
+	return &Response{
+		t:          c.t,
+		client:     c,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		Duration:   duration,
+		Raw:        resp,
+	}
+}
+
+// Text returns the body as a string.
+func (r *Response) Text() string {
+	return string(r.Body)
+}
+
+// Cookies returns the cookies set by the response.
+func (r *Response) Cookies() []*http.Cookie {
+	return r.Raw.Cookies()
+}
+
+// DecodeJSON decodes the body into target, failing the test if it is not valid JSON.
+func (r *Response) DecodeJSON(target any) *Response {
+	r.t.Helper()
+	DecodeJSON(r.t, bytes.NewReader(r.Body), target)
+	return r
+}
+
+// RedirectChain returns the URLs visited to obtain the response: the requested URL
+// first, then every redirect target, ending with the URL that produced the response.
+func (r *Response) RedirectChain() []string {
+	var chain []string
+	for req := r.Raw.Request; req != nil; req = req.Response.Request {
+		chain = append(chain, req.URL.String())
+		if req.Response == nil {
+			break
+		}
+	}
+	slices.Reverse(chain)
+	return chain
+}
@@ -7,34 +7,196 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"sync"
 	"testing"
 )
 
+// ServerOption configures a Server.
+type ServerOption func(*serverConfig)
+
+type serverConfig struct {
+	inProcess bool
+	tls       bool
+	http2     bool
+	mutualTLS bool
+}
+
+// InProcess serves requests by calling the handler directly instead of listening on
+// a socket. The client and base URL work as with a real server, so tests do not need
+// to know which mode they run in.
+func InProcess() ServerOption {
+	return func(c *serverConfig) {
+		c.inProcess = true
+	}
+}
+
 // Server holds the state of a test HTTP server.
 type Server struct {
-	baseURL string
-	client  *http.Client
+	baseURL   string
+	client    *http.Client
+	inProcess bool
+	transport *http.Transport // untracked transport of client, nil in process
+	ca        *certAuthority  // set for MutualTLS
+
+	identityTransports []*http.Transport // created by ClientFor, closed with the server
+
+	mu          sync.Mutex
+	bodies      map[*trackedBody]string
+	conns       map[net.Conn]http.ConnState
+	inFlight    map[*http.Request]string
+	activeAtEnd []string
+	closed      bool
 }
 
 func (s *Server) BaseURL() string      { return s.baseURL }
 func (s *Server) Client() *http.Client { return s.client }
 func (s *Server) Close() error         { return nil } // httptest.Server is closed by teardown
 
+// InProcess reports whether the server dispatches requests without a network listener.
+func (s *Server) InProcess() bool { return s.inProcess }
+
 // NewServer creates a new httptest.Server and returns a Server helper and a cleanup function.
-func NewServer(t testing.TB, handler http.Handler) (*Server, func() error) {
-	server := httptest.NewServer(handler)
+// Responses received through Client and connections accepted by the server are tracked
+// so that leaks can be reported with OpenBodies and ActiveConns.
+func NewServer(t testing.TB, handler http.Handler, opts ...ServerOption) (*Server, func() error) {
+	var cfg serverConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s := &Server{
+		bodies:   make(map[*trackedBody]string),
+		conns:    make(map[net.Conn]http.ConnState),
+		inFlight: make(map[*http.Request]string),
+	}
+	if cfg.inProcess {
+		if cfg.tls {
+			t.Fatalf("InProcess cannot be combined with TLS, HTTP2 or MutualTLS")
+			return s, func() error { return nil }
+		}
+		return s, s.startInProcess(handler)
+	}
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.ConnState = s.trackConn
+	server.EnableHTTP2 = cfg.http2
+	if cfg.mutualTLS {
+		if err := s.configureMutualTLS(server); err != nil {
+			t.Fatalf("failed to configure mutual TLS: %v", err)
+			return s, func() error { return nil }
+		}
+	}
+	if cfg.tls {
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+
+	client := *server.Client()
+	s.transport = client.Transport.(*http.Transport)
+	if s.ca != nil {
+		s.transport.TLSClientConfig.RootCAs = s.ca.pool
+	}
+	client.Transport = &trackingTransport{base: client.Transport, server: s}
+	s.baseURL = server.URL
+	s.client = &client
 
 	cleanup := func() error {
+		s.mu.Lock()
+		s.activeAtEnd = s.activeConnsLocked()
+		s.closed = true
+		transports := s.identityTransports
+		s.mu.Unlock()
 		server.Close()
+		for _, transport := range transports {
+			transport.CloseIdleConnections()
+		}
 		return nil
 	}
+	return s, cleanup
+}
+
+// OpenBodies returns the requests whose response bodies have not been closed.
+func (s *Server) OpenBodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	open := make([]string, 0, len(s.bodies))
+	for _, req := range s.bodies {
+		open = append(open, req)
+	}
+	slices.Sort(open)
+	return open
+}
+
+// ActiveConns returns the remote addresses of connections still serving a request,
+// or the requests still being handled by an in-process server. Once the server is
+// closed it returns the connections that were active at that time.
+func (s *Server) ActiveConns() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return slices.Clone(s.activeAtEnd)
+	}
+	return s.activeConnsLocked()
+}
+
+func (s *Server) activeConnsLocked() []string {
+	var active []string
+	for conn, state := range s.conns {
+		if state == http.StateActive {
+			active = append(active, conn.RemoteAddr().String())
+		}
+	}
+	for _, req := range s.inFlight {
+		active = append(active, req)
+	}
+	slices.Sort(active)
+	return active
+}
+
+func (s *Server) trackConn(conn net.Conn, state http.ConnState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch state {
+	case http.StateClosed, http.StateHijacked:
+		delete(s.conns, conn)
+	default:
+		s.conns[conn] = state
+	}
+}
+
+// trackingTransport records response bodies until they are closed.
+type trackingTransport struct {
+	base   http.RoundTripper
+	server *Server
+}
+
+func (t *trackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body := &trackedBody{ReadCloser: resp.Body, server: t.server}
+	t.server.mu.Lock()
+	t.server.bodies[body] = req.Method + " " + req.URL.RequestURI()
+	t.server.mu.Unlock()
+	resp.Body = body
+	return resp, nil
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	server *Server
+}
 
-	return &Server{
-		baseURL: server.URL,
-		client:  server.Client(),
-	}, cleanup
+func (b *trackedBody) Close() error {
+	b.server.mu.Lock()
+	delete(b.server.bodies, b)
+	b.server.mu.Unlock()
+	return b.ReadCloser.Close()
 }
 
 // EncodeJSON encodes the given value into an io.Reader.
@@ -99,3 +99,24 @@ func (m *mockTB) Fatal(_ ...any) {
 func (m *mockTB) Fatalf(_ string, _ ...any) {
 	m.failed = true
 }
+
+func TestServer_Tracking(t *testing.T) {
+	srv, cleanup := NewServer(t, http.NotFoundHandler())
+
+	resp, err := srv.Client().Get(srv.BaseURL() + "/items?page=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if open := srv.OpenBodies(); len(open) != 1 || open[0] != "GET /items?page=2" {
+		t.Errorf("expected open body to be tracked, got %v", open)
+	}
+	_ = resp.Body.Close()
+	if open := srv.OpenBodies(); len(open) != 0 {
+		t.Errorf("expected closed body to be untracked, got %v", open)
+	}
+
+	_ = cleanup()
+	if active := srv.ActiveConns(); len(active) != 0 {
+		t.Errorf("expected no active connections after shutdown, got %v", active)
+	}
+}
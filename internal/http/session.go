@@ -0,0 +1,134 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"testing"
+)
+
+// defaultMaxRedirects is the number of redirects a session follows by default.
+// http.Client's default policy gives up on the 10th redirect; a session follows it
+// and fails on the 11th, so that MaxRedirects(n) always means n redirects followed.
+const defaultMaxRedirects = 10
+
+// SessionOption configures a Session.
+type SessionOption func(*sessionConfig)
+
+type sessionConfig struct {
+	maxRedirects int
+	login        func(*Session)
+}
+
+// MaxRedirects sets how many redirects a session follows per request; the request
+// fails on redirect n+1. The default is 10. With n == 0 redirects are not followed
+// and the 3xx response itself is returned.
+func MaxRedirects(n int) SessionOption {
+	return func(c *sessionConfig) {
+		c.maxRedirects = n
+	}
+}
+
+// NoRedirects makes the session return redirect responses instead of following them.
+func NoRedirects() SessionOption {
+	return MaxRedirects(0)
+}
+
+// WithLogin runs login once, when the session is created.
+func WithLogin(login func(*Session)) SessionOption {
+	return func(c *sessionConfig) {
+		c.login = login
+	}
+}
+
+// Session is a Client with its own cookie jar, modelling a single browser user.
+// Cookies set by responses are sent with later requests of the same session only.
+type Session struct {
+	*Client
+	name string
+}
+
+// NewSession creates a session named name that sends requests to baseURL through a
+// copy of client with a fresh cookie jar. Failures are reported to t.
+func NewSession(t testing.TB, name, baseURL string, client *http.Client, opts ...SessionOption) *Session {
+	t.Helper()
+	cfg := sessionConfig{maxRedirects: defaultMaxRedirects}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	c := *client
+	c.Jar = newJar()
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if cfg.maxRedirects == 0 {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > cfg.maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", cfg.maxRedirects)
+		}
+		return nil
+	}
+
+	s := &Session{Client: NewClient(t, baseURL, &c), name: name}
+	if cfg.login != nil {
+		cfg.login(s)
+	}
+	return s
+}
+
+// Name returns the name the session was created with.
+func (s *Session) Name() string {
+	return s.name
+}
+
+// WithT returns a view of the session that reports failures to t. The view shares
+// the cookie jar and redirect policy of s.
+func (s *Session) WithT(t testing.TB) *Session {
+	c := *s.Client
+	c.t = t
+	return &Session{Client: &c, name: s.name}
+}
+
+// Cookies returns the cookies the session would send to the base URL.
+func (s *Session) Cookies() []*http.Cookie {
+	return s.client.Jar.Cookies(s.base())
+}
+
+// Cookie returns the named cookie the session would send to the base URL, or nil.
+func (s *Session) Cookie(name string) *http.Cookie {
+	for _, c := range s.Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// SetCookie stores cookie in the session as if the base URL had set it.
+func (s *Session) SetCookie(cookie *http.Cookie) {
+	s.client.Jar.SetCookies(s.base(), []*http.Cookie{cookie})
+}
+
+// ClearCookies forgets every cookie of the session, e.g. to model a closed browser.
+func (s *Session) ClearCookies() {
+	s.client.Jar = newJar()
+}
+
+func (s *Session) base() *url.URL {
+	u, err := url.Parse(s.baseURL)
+	if err != nil {
+		s.t.Fatalf("session %s: invalid base URL %q: %v", s.name, s.baseURL, err)
+	}
+	return u
+}
+
+func newJar() http.CookieJar {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		// cookiejar.New never fails without a PublicSuffixList
+		panic(err)
+	}
+	return jar
+}
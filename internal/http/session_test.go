@@ -0,0 +1,114 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func sessionHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "user", Value: r.URL.Query().Get("user"), Path: "/"})
+		http.Redirect(w, r, "/home", http.StatusFound)
+	})
+	mux.HandleFunc("/home", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("user")
+		if err != nil {
+			http.Error(w, "anonymous", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("hello " + c.Value))
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	// /hops/{n} redirects n times before responding
+	mux.HandleFunc("/hops/{n}", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.PathValue("n"))
+		if n > 0 {
+			http.Redirect(w, r, "/hops/"+strconv.Itoa(n-1), http.StatusFound)
+		}
+	})
+	return mux
+}
+
+func TestSession(t *testing.T) {
+	srv, cleanup := NewServer(t, sessionHandler())
+	defer func() { _ = cleanup() }()
+
+	t.Run("IsolatedJars", func(t *testing.T) {
+		logins := 0
+		alice := NewSession(t, "alice", srv.BaseURL(), srv.Client(), WithLogin(func(s *Session) {
+			logins++
+			s.Get("/login").Query("user", "alice").Do().ExpectStatus(http.StatusOK)
+		}))
+		bob := NewSession(t, "bob", srv.BaseURL(), srv.Client())
+
+		alice.Get("/home").Do().ExpectStatus(http.StatusOK)
+		bob.Get("/home").Do().ExpectStatus(http.StatusUnauthorized)
+		if logins != 1 || alice.Name() != "alice" {
+			t.Errorf("expected one login for alice, got %d (%s)", logins, alice.Name())
+		}
+		if c := alice.Cookie("user"); c == nil || c.Value != "alice" {
+			t.Errorf("expected user cookie, got %v", c)
+		}
+		if srv.Client().Jar != nil {
+			t.Error("expected the server client to stay without a jar")
+		}
+
+		alice.ClearCookies()
+		alice.Get("/home").Do().ExpectStatus(http.StatusUnauthorized)
+		alice.SetCookie(&http.Cookie{Name: "user", Value: "mallory"})
+		if got := alice.Get("/home").Do().Text(); got != "hello mallory" {
+			t.Errorf("expected cookie set by test to be sent, got %q", got)
+		}
+	})
+
+	t.Run("RedirectChain", func(t *testing.T) {
+		s := NewSession(t, "chain", srv.BaseURL(), srv.Client())
+		resp := s.Get("/login").Query("user", "carol").Do()
+		chain := resp.RedirectChain()
+		want := []string{srv.BaseURL() + "/login?user=carol", srv.BaseURL() + "/home"}
+		if len(chain) != len(want) || chain[0] != want[0] || chain[1] != want[1] {
+			t.Errorf("expected chain %v, got %v", want, chain)
+		}
+	})
+
+	t.Run("NoRedirects", func(t *testing.T) {
+		s := NewSession(t, "manual", srv.BaseURL(), srv.Client(), NoRedirects())
+		resp := s.Get("/login").Query("user", "dave").Do().
+			ExpectStatus(http.StatusFound).
+			ExpectHeader("Location", "/home")
+		if len(resp.RedirectChain()) != 1 {
+			t.Errorf("expected no redirects to be followed, got %v", resp.RedirectChain())
+		}
+		if s.Cookie("user") == nil {
+			t.Error("expected cookies of redirect responses to be stored")
+		}
+	})
+
+	t.Run("MaxRedirects", func(t *testing.T) {
+		mockT := &mockTB{TB: t}
+		s := NewSession(mockT, "loop", srv.BaseURL(), srv.Client(), MaxRedirects(2))
+		if s.Get("/loop").Do() != nil || !mockT.failed {
+			t.Fatal("expected redirect loop to fail the request")
+		}
+
+		for _, tc := range []struct {
+			opts []SessionOption
+			max  int
+		}{{nil, 10}, {[]SessionOption{MaxRedirects(2)}, 2}} {
+			s := NewSession(t, "hops", srv.BaseURL(), srv.Client(), tc.opts...)
+			if chain := s.Get("/hops/" + strconv.Itoa(tc.max)).Do().RedirectChain(); len(chain) != tc.max+1 {
+				t.Errorf("expected %d redirects to be followed, got %v", tc.max, chain)
+			}
+			mockT := &mockTB{TB: t}
+			NewSession(mockT, "hops", srv.BaseURL(), srv.Client(), tc.opts...).Get("/hops/" + strconv.Itoa(tc.max+1)).Do()
+			if !mockT.failed {
+				t.Errorf("expected redirect %d to fail the request", tc.max+1)
+			}
+		}
+	})
+}
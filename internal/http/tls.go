@@ -0,0 +1,195 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// TLS serves over HTTPS using the httptest certificate, which Client trusts.
+func TLS() ServerOption {
+	return func(c *serverConfig) {
+		c.tls = true
+	}
+}
+
+// HTTP2 serves over HTTPS with HTTP/2 enabled; Client negotiates HTTP/2.
+func HTTP2() ServerOption {
+	return func(c *serverConfig) {
+		c.tls = true
+		c.http2 = true
+	}
+}
+
+// MutualTLS serves over HTTPS with a certificate issued by a throwaway CA and
+// verifies client certificates issued by the same CA. Certificates are optional
+// at the TLS layer so handlers can test how anonymous clients are rejected; use
+// ClientFor to obtain a client presenting a certificate.
+func MutualTLS() ServerOption {
+	return func(c *serverConfig) {
+		c.tls = true
+		c.mutualTLS = true
+	}
+}
+
+// certValidity bounds the lifetime of throwaway certificates; the start is
+// backdated to tolerate clock skew.
+const certValidity = 24 * time.Hour
+
+// certAuthority issues certificates for a single mutual TLS server.
+type certAuthority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+
+	mu      sync.Mutex
+	clients map[string]tls.Certificate
+}
+
+func newCertAuthority() (*certAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := certTemplate("scg-test-kit throwaway CA")
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &certAuthority{cert: cert, key: key, pool: pool, clients: make(map[string]tls.Certificate)}, nil
+}
+
+// serverCertificate issues a certificate for the loopback addresses and the
+// example.com names httptest servers answer to.
+func (ca *certAuthority) serverCertificate() (tls.Certificate, error) {
+	tmpl := certTemplate("scg-test-kit server")
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	tmpl.DNSNames = []string{"localhost", "example.com", "*.example.com"}
+	tmpl.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	return ca.issue(tmpl)
+}
+
+// clientCertificate returns the certificate for identity, issuing it on first use.
+// The identity is the certificate's common name.
+func (ca *certAuthority) clientCertificate(identity string) (tls.Certificate, error) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if cert, ok := ca.clients[identity]; ok {
+		return cert, nil
+	}
+	tmpl := certTemplate(identity)
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	cert, err := ca.issue(tmpl)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("client certificate for %q: %w", identity, err)
+	}
+	ca.clients[identity] = cert
+	return cert, nil
+}
+
+func (ca *certAuthority) issue(tmpl *x509.Certificate) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der, ca.cert.Raw}, PrivateKey: key, Leaf: leaf}, nil
+}
+
+func certTemplate(commonName string) *x509.Certificate {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+	}
+}
+
+// configureMutualTLS makes server present a certificate of a fresh CA and verify
+// client certificates against it.
+func (s *Server) configureMutualTLS(server *httptest.Server) error {
+	ca, err := newCertAuthority()
+	if err != nil {
+		return err
+	}
+	cert, err := ca.serverCertificate()
+	if err != nil {
+		return err
+	}
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    ca.pool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	s.ca = ca
+	return nil
+}
+
+// RootCAs returns the pool clients must trust to reach the server, or nil if it
+// does not serve TLS.
+func (s *Server) RootCAs() *x509.CertPool {
+	if s.transport == nil || s.transport.TLSClientConfig == nil {
+		return nil
+	}
+	return s.transport.TLSClientConfig.RootCAs
+}
+
+// ClientCertificate returns the client certificate for identity, issuing it on
+// first use. It requires MutualTLS.
+func (s *Server) ClientCertificate(identity string) (tls.Certificate, error) {
+	if s.ca == nil {
+		return tls.Certificate{}, fmt.Errorf("server does not use mutual TLS")
+	}
+	return s.ca.clientCertificate(identity)
+}
+
+// ClientFor returns a client like Client that presents the certificate of identity.
+// It requires MutualTLS.
+func (s *Server) ClientFor(identity string) (*http.Client, error) {
+	cert, err := s.ClientCertificate(identity)
+	if err != nil {
+		return nil, err
+	}
+	transport := s.transport.Clone()
+	transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, fmt.Errorf("server is closed")
+	}
+	s.identityTransports = append(s.identityTransports, transport)
+	client := *s.client
+	client.Transport = &trackingTransport{base: transport, server: s}
+	return &client, nil
+}
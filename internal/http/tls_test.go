@@ -0,0 +1,92 @@
+// nolint:revive // package name is intentional
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func protoHandler(w http.ResponseWriter, r *http.Request) {
+	identity := "anonymous"
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		identity = r.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	_, _ = fmt.Fprintf(w, "%s %t %s", r.Proto, r.TLS != nil, identity)
+}
+
+func TestServer_TLS(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts []ServerOption
+		want string
+	}{
+		"Plain":     {nil, "HTTP/1.1 false anonymous"},
+		"TLS":       {[]ServerOption{TLS()}, "HTTP/1.1 true anonymous"},
+		"HTTP2":     {[]ServerOption{HTTP2()}, "HTTP/2.0 true anonymous"},
+		"MutualTLS": {[]ServerOption{MutualTLS()}, "HTTP/1.1 true anonymous"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv, cleanup := NewServer(t, http.HandlerFunc(protoHandler), tc.opts...)
+			defer func() { _ = cleanup() }()
+
+			if got := NewClient(t, srv.BaseURL(), srv.Client()).Get("/").Do().Text(); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+			if tls := strings.HasPrefix(srv.BaseURL(), "https://"); tls != (srv.RootCAs() != nil) {
+				t.Errorf("expected root CAs exactly for HTTPS servers, got %v for %s", srv.RootCAs(), srv.BaseURL())
+			}
+		})
+	}
+}
+
+func TestServer_MutualTLS(t *testing.T) {
+	srv, cleanup := NewServer(t, http.HandlerFunc(protoHandler), MutualTLS())
+	defer func() { _ = cleanup() }()
+
+	for _, identity := range []string{"alice", "bob"} {
+		client, err := srv.ClientFor(identity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "HTTP/1.1 true " + identity
+		if got := NewClient(t, srv.BaseURL(), client).Get("/").Do().Text(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+
+	first, _ := srv.ClientCertificate("alice")
+	again, _ := srv.ClientCertificate("alice")
+	if first.Leaf != again.Leaf {
+		t.Error("expected the certificate of an identity to be reused")
+	}
+
+	plain, cleanupPlain := NewServer(t, http.HandlerFunc(protoHandler), TLS())
+	defer func() { _ = cleanupPlain() }()
+	if _, err := plain.ClientFor("alice"); err == nil {
+		t.Error("expected ClientFor to require mutual TLS")
+	}
+
+	// A certificate from another CA is rejected during the handshake
+	other, cleanupOther := NewServer(t, http.HandlerFunc(protoHandler), MutualTLS())
+	defer func() { _ = cleanupOther() }()
+	foreign, _ := other.ClientFor("mallory")
+	foreign.Transport.(*trackingTransport).base.(*http.Transport).TLSClientConfig.RootCAs = srv.RootCAs()
+	if _, err := foreign.Get(srv.BaseURL()); err == nil {
+		t.Error("expected certificate of a foreign CA to be rejected")
+	}
+
+	_ = cleanup()
+	if _, err := srv.ClientFor("carol"); err == nil {
+		t.Error("expected ClientFor to fail after cleanup")
+	}
+}
+
+func TestServer_InProcessTLS(t *testing.T) {
+	mockT := &mockTB{TB: t}
+	_, cleanup := NewServer(mockT, http.NotFoundHandler(), InProcess(), TLS())
+	_ = cleanup()
+	if !mockT.failed {
+		t.Error("expected InProcess with TLS to fail")
+	}
+}
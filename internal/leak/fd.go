@@ -0,0 +1,113 @@
+package leak
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrUnsupported is returned by OpenFDs on platforms without /proc/self/fd.
+var ErrUnsupported = errors.New("file descriptor inspection not supported on this platform")
+
+// runtimeFDKinds are descriptors the Go runtime creates lazily (netpoller, timers)
+// and keeps for the life of the process.
+var runtimeFDKinds = []string{"anon_inode:[eventpoll]", "anon_inode:[eventfd]", "anon_inode:[timerfd]"}
+
+// FD is an open file descriptor.
+type FD struct {
+	Num    int
+	Kind   string
+	Target string
+}
+
+func (fd FD) String() string {
+	return fmt.Sprintf("fd %d: %s %s", fd.Num, fd.Kind, fd.Target)
+}
+
+// FDSnapshot records the file descriptors open at the time of the call.
+type FDSnapshot map[int]FD
+
+// TakeFDs returns a snapshot of the open file descriptors.
+func TakeFDs() (FDSnapshot, error) {
+	fds, err := OpenFDs()
+	if err != nil {
+		return nil, err
+	}
+	snap := make(FDSnapshot, len(fds))
+	for _, fd := range fds {
+		snap[fd.Num] = fd
+	}
+	return snap, nil
+}
+
+// LeakedFDs returns descriptors that are open now but were not open, or pointed
+// elsewhere, in baseline.
+func LeakedFDs(baseline FDSnapshot) ([]FD, error) {
+	fds, err := OpenFDs()
+	if err != nil {
+		return nil, err
+	}
+	var leaked []FD
+	for _, fd := range fds {
+		if prev, ok := baseline[fd.Num]; ok && prev == fd {
+			continue
+		}
+		if slices.Contains(runtimeFDKinds, fd.Kind) {
+			continue
+		}
+		leaked = append(leaked, fd)
+	}
+	return leaked, nil
+}
+
+// Resource is something other than a descriptor left open, e.g. an unclosed response body.
+type Resource struct {
+	Kind   string
+	Target string
+}
+
+// ResourceError lists leaked descriptors and resources by kind and target.
+type ResourceError struct {
+	Leaked []Resource
+}
+
+func (e *ResourceError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "found %d leaked resource(s):", len(e.Leaked))
+	for _, r := range e.Leaked {
+		fmt.Fprintf(&b, "\n  %s: %s", r.Kind, r.Target)
+	}
+	return b.String()
+}
+
+// CheckResources retries until neither a new descriptor in baseline nor any resource
+// reported by others is open, or grace elapses. A nil baseline skips descriptors.
+func CheckResources(baseline FDSnapshot, others func() []Resource, grace time.Duration) error {
+	deadline := time.Now().Add(grace)
+	delay := time.Millisecond
+	for {
+		var leaked []Resource
+		if others != nil {
+			leaked = append(leaked, others()...)
+		}
+		if baseline != nil {
+			fds, err := LeakedFDs(baseline)
+			if err != nil {
+				return err
+			}
+			for _, fd := range fds {
+				leaked = append(leaked, Resource{Kind: fd.Kind, Target: fmt.Sprintf("%s (fd %d)", fd.Target, fd.Num)})
+			}
+		}
+		if len(leaked) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return &ResourceError{Leaked: leaked}
+		}
+		time.Sleep(delay)
+		delay = min(delay*2, 100*time.Millisecond)
+	}
+}
@@ -0,0 +1,107 @@
+//go:build linux
+
+package leak
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OpenFDs lists the open file descriptors of the process from /proc/self/fd.
+// Sockets are resolved to their TCP endpoints where possible.
+func OpenFDs() ([]FD, error) {
+	dir, err := os.Open("/proc/self/fd")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupported, err)
+	}
+	defer func() { _ = dir.Close() }()
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	var sockets map[string]string
+	fds := make([]FD, 0, len(names))
+	for _, name := range names {
+		num, err := strconv.Atoi(name)
+		if err != nil || num == int(dir.Fd()) {
+			continue
+		}
+		link, err := os.Readlink("/proc/self/fd/" + name)
+		if err != nil {
+			// Closed between listing and reading
+			continue
+		}
+
+		fd := FD{Num: num, Kind: "file", Target: link}
+		switch {
+		case strings.HasPrefix(link, "socket:["):
+			if sockets == nil {
+				sockets = tcpSockets()
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")
+			fd.Kind = "socket"
+			if endpoint, ok := sockets[inode]; ok {
+				fd.Target = endpoint
+			}
+		case strings.HasPrefix(link, "pipe:["):
+			fd.Kind = "pipe"
+		case strings.HasPrefix(link, "anon_inode:"):
+			fd.Kind = link
+			fd.Target = ""
+		}
+		fds = append(fds, fd)
+	}
+	return fds, nil
+}
+
+// tcpSockets maps socket inodes to "tcp local -> remote" from /proc/self/net.
+func tcpSockets() map[string]string {
+	sockets := make(map[string]string)
+	for _, proto := range []string{"tcp", "tcp6"} {
+		f, err := os.Open("/proc/self/net/" + proto)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Scan() // header
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 {
+				continue
+			}
+			local, remote, inode := parseAddr(fields[1]), parseAddr(fields[2]), fields[9]
+			if fields[3] == "0A" {
+				sockets[inode] = fmt.Sprintf("tcp listen %s", local)
+			} else {
+				sockets[inode] = fmt.Sprintf("tcp %s -> %s", local, remote)
+			}
+		}
+		_ = f.Close()
+	}
+	return sockets
+}
+
+// parseAddr decodes "0100007F:1F90" into "127.0.0.1:8080". The address is stored
+// as 32-bit words in host (little-endian) order.
+func parseAddr(s string) string {
+	hexIP, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return s
+	}
+	raw, err := hex.DecodeString(hexIP)
+	port, perr := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil || perr != nil || len(raw)%4 != 0 {
+		return s
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	return net.JoinHostPort(ip.String(), strconv.FormatUint(port, 10))
+}
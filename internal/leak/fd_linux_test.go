@@ -0,0 +1,67 @@
+//go:build linux
+
+package leak
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckResources_FDs(t *testing.T) {
+	baseline, err := TakeFDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "data.txt")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = CheckResources(baseline, nil, 10*time.Millisecond)
+	var leakErr *ResourceError
+	if !errors.As(err, &leakErr) {
+		t.Fatalf("expected ResourceError, got %v", err)
+	}
+	for _, want := range []string{"file: " + path, "socket: tcp listen " + ln.Addr().String()} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected report to contain %q:\n%v", want, err)
+		}
+	}
+
+	_ = f.Close()
+	_ = ln.Close()
+	if err := CheckResources(baseline, nil, time.Second); err != nil {
+		t.Errorf("expected no leaks after closing, got %v", err)
+	}
+}
+
+func TestCheckResources_Others(t *testing.T) {
+	open := []Resource{{Kind: "http-body", Target: "GET /items"}}
+	err := CheckResources(nil, func() []Resource { return open }, 0)
+	if err == nil || err.Error() != "found 1 leaked resource(s):\n  http-body: GET /items" {
+		t.Errorf("unexpected report: %v", err)
+	}
+}
+
+func TestParseAddr(t *testing.T) {
+	tests := map[string]string{
+		"0100007F:1F90":                         "127.0.0.1:8080",
+		"00000000000000000000000001000000:0050": "[::1]:80",
+	}
+	for in, want := range tests {
+		if got := parseAddr(in); got != want {
+			t.Errorf("parseAddr(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
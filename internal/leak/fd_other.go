@@ -0,0 +1,8 @@
+//go:build !linux
+
+package leak
+
+// OpenFDs is not supported outside Linux.
+func OpenFDs() ([]FD, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,156 @@
+// Package leak detects goroutines and file descriptors left behind by a test.
+package leak
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultIgnores are stack substrings of goroutines that outlive a test by design,
+// such as idle HTTP keep-alive connections of test clients. Goroutines of the test
+// runner itself are recognised by their entry function instead; see runnerEntries.
+var DefaultIgnores = []string{
+	"net/http.(*persistConn).readLoop",
+	"net/http.(*persistConn).writeLoop",
+	"created by os/signal.Notify",
+	"runtime.ensureSigM",
+}
+
+// runnerEntries are the entry functions of the goroutines the testing package starts
+// for tests and benchmarks. Matching the entry rather than the "created by" line
+// keeps goroutines the code under test starts from being mistaken for them.
+var runnerEntries = []string{
+	"testing.tRunner",
+	"testing.(*B).run1.func1",
+}
+
+// Goroutine is a parsed goroutine from a runtime stack dump.
+type Goroutine struct {
+	ID    uint64
+	State string
+	Top   string // innermost function
+	Entry string // function the goroutine was started with
+	Stack string
+}
+
+// Snapshot records the goroutines running at the time of the call.
+type Snapshot map[uint64]bool
+
+// Take returns a snapshot of the currently running goroutines.
+func Take() Snapshot {
+	snap := make(Snapshot)
+	for _, g := range Goroutines() {
+		snap[g.ID] = true
+	}
+	return snap
+}
+
+// Goroutines returns all running goroutines except the calling one.
+func Goroutines() []Goroutine {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	var out []Goroutine
+	// The first block is always the calling goroutine.
+	for i, block := range bytes.Split(buf, []byte("\n\n")) {
+		if i == 0 {
+			continue
+		}
+		if g, ok := parse(string(block)); ok {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// parse parses a block of the form "goroutine 12 [chan receive, 2 minutes]:\nmain.f(...)".
+func parse(block string) (Goroutine, bool) {
+	header, rest, _ := strings.Cut(strings.TrimSpace(block), "\n")
+	header = strings.TrimPrefix(header, "goroutine ")
+	idText, state, ok := strings.Cut(header, " [")
+	if !ok {
+		return Goroutine{}, false
+	}
+	id, err := strconv.ParseUint(idText, 10, 64)
+	if err != nil {
+		return Goroutine{}, false
+	}
+	state, _, _ = strings.Cut(strings.TrimSuffix(state, "]:"), ",")
+
+	var top, entry string
+	for _, line := range strings.Split(rest, "\n") {
+		// Skip file positions, the creator and the marker of elided frames
+		if line == "" || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "created by ") ||
+			strings.HasPrefix(line, "...") {
+			continue
+		}
+		if i := strings.LastIndex(line, "("); i > 0 {
+			line = line[:i]
+		}
+		if top == "" {
+			top = line
+		}
+		entry = line
+	}
+	return Goroutine{ID: id, State: state, Top: top, Entry: entry, Stack: strings.TrimSpace(block)}, true
+}
+
+// Leaked returns the goroutines not present in baseline whose stack does not
+// contain any of the ignore substrings, leaving out those running tests.
+func Leaked(baseline Snapshot, ignore []string) []Goroutine {
+	var leaked []Goroutine
+	for _, g := range Goroutines() {
+		if baseline[g.ID] || slices.Contains(runnerEntries, g.Entry) {
+			continue
+		}
+		if slices.ContainsFunc(ignore, func(s string) bool { return strings.Contains(g.Stack, s) }) {
+			continue
+		}
+		leaked = append(leaked, g)
+	}
+	return leaked
+}
+
+// CheckGoroutines retries Leaked until no goroutine is leaked or grace elapses,
+// giving goroutines that are shutting down time to exit.
+func CheckGoroutines(baseline Snapshot, ignore []string, grace time.Duration) error {
+	deadline := time.Now().Add(grace)
+	delay := time.Millisecond
+	for {
+		leaked := Leaked(baseline, ignore)
+		if len(leaked) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return &GoroutineError{Leaked: leaked}
+		}
+		time.Sleep(delay)
+		delay = min(delay*2, 100*time.Millisecond)
+	}
+}
+
+// GoroutineError lists leaked goroutines with their stacks.
+type GoroutineError struct {
+	Leaked []Goroutine
+}
+
+func (e *GoroutineError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "found %d leaked goroutine(s):", len(e.Leaked))
+	for _, g := range e.Leaked {
+		fmt.Fprintf(&b, "\n\n%s", g.Stack)
+	}
+	return b.String()
+}
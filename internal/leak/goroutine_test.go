@@ -0,0 +1,102 @@
+package leak
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func leakyWorker(stop <-chan struct{}) {
+	<-stop
+}
+
+// startWorker starts leakyWorker and waits until it is running.
+func startWorker(stop <-chan struct{}) {
+	started := make(chan struct{})
+	go func() {
+		close(started)
+		leakyWorker(stop)
+	}()
+	<-started
+}
+
+func TestCheckGoroutines(t *testing.T) {
+	t.Run("Leak", func(t *testing.T) {
+		baseline := Take()
+		stop := make(chan struct{})
+		defer close(stop)
+		startWorker(stop)
+
+		err := CheckGoroutines(baseline, DefaultIgnores, 20*time.Millisecond)
+		var leakErr *GoroutineError
+		if !errors.As(err, &leakErr) {
+			t.Fatalf("expected GoroutineError, got %v", err)
+		}
+		if len(leakErr.Leaked) != 1 || leakErr.Leaked[0].State != "chan receive" ||
+			!strings.HasSuffix(leakErr.Leaked[0].Top, "leak.leakyWorker") {
+			t.Errorf("unexpected leak report: %+v", leakErr.Leaked)
+		}
+		if !strings.Contains(err.Error(), "found 1 leaked goroutine(s)") {
+			t.Errorf("unexpected message: %v", err)
+		}
+	})
+
+	t.Run("Ignored", func(t *testing.T) {
+		baseline := Take()
+		stop := make(chan struct{})
+		defer close(stop)
+		startWorker(stop)
+
+		if err := CheckGoroutines(baseline, []string{"leak.leakyWorker"}, 0); err != nil {
+			t.Errorf("expected ignored goroutine not to be reported, got %v", err)
+		}
+	})
+
+	t.Run("SubtestsIgnored", func(t *testing.T) {
+		baseline := Take()
+		stop := make(chan struct{})
+		defer close(stop)
+		// A parallel subtest stays paused on its tRunner goroutine until this one returns
+		t.Run("Paused", func(t *testing.T) { t.Parallel() })
+		startWorker(stop)
+
+		err := CheckGoroutines(baseline, DefaultIgnores, 0)
+		var leakErr *GoroutineError
+		if !errors.As(err, &leakErr) || len(leakErr.Leaked) != 1 || !strings.HasSuffix(leakErr.Leaked[0].Top, "leak.leakyWorker") {
+			t.Errorf("expected only the worker to be reported, got %v", err)
+		}
+	})
+
+	t.Run("ExitsWithinGrace", func(t *testing.T) {
+		baseline := Take()
+		stop := make(chan struct{})
+		startWorker(stop)
+		time.AfterFunc(10*time.Millisecond, func() { close(stop) })
+
+		if err := CheckGoroutines(baseline, DefaultIgnores, 5*time.Second); err != nil {
+			t.Errorf("expected goroutine exiting within grace period to pass, got %v", err)
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	g, ok := parse("goroutine 42 [select, 3 minutes]:\nmain.worker(0xc000010000)\n\t/src/main.go:10 +0x1d\ncreated by main.main in goroutine 1")
+	if !ok {
+		t.Fatal("expected block to parse")
+	}
+	if g.ID != 42 || g.State != "select" || g.Top != "main.worker" || g.Entry != "main.worker" {
+		t.Errorf("unexpected goroutine: %+v", g)
+	}
+
+	// Started from a test body running under testing.(*T).Run, but not a test itself
+	g, _ = parse("goroutine 7 [chan receive]:\nmain.worker(...)\n\t/src/main.go:10\nmain.start.func1()\n\t/src/main.go:4 +0x1d\ncreated by testing.(*T).Run in goroutine 1")
+	if g.Top != "main.worker" || g.Entry != "main.start.func1" || slices.Contains(runnerEntries, g.Entry) {
+		t.Errorf("unexpected goroutine: %+v", g)
+	}
+	g, _ = parse("goroutine 8 [chan receive]:\ntesting.(*T).Parallel(0xc0)\n\t/go/testing.go:1\ntesting.tRunner(0xc0, 0x1)\n\t/go/testing.go:2 +0xea\ncreated by testing.(*T).Run in goroutine 7")
+	if !slices.Contains(runnerEntries, g.Entry) {
+		t.Errorf("expected a test goroutine, got entry %q", g.Entry)
+	}
+}
@@ -0,0 +1,190 @@
+package logcapture
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Record is a captured log record.
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   []slog.Attr // flattened, in the order they were added
+}
+
+// Attr returns the value of the attribute with the given dotted key.
+func (r Record) Attr(key string) (slog.Value, bool) {
+	for _, a := range r.Attrs {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+// String formats the record as a single line, e.g.
+// `12:00:00.000 WARN retrying attempt=2 err="connection refused"`.
+func (r Record) String() string {
+	var b strings.Builder
+	if !r.Time.IsZero() {
+		b.WriteString(r.Time.Format("15:04:05.000 "))
+	}
+	b.WriteString(r.Level.String())
+	b.WriteByte(' ')
+	b.WriteString(r.Message)
+	for _, a := range r.Attrs {
+		b.WriteByte(' ')
+		b.WriteString(a.Key)
+		b.WriteByte('=')
+		b.WriteString(quote(a.Value.String()))
+	}
+	return b.String()
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// Capture buffers the records logged through its handler and reports assertion
+// failures to a test.
+type Capture struct {
+	t     testing.TB
+	store *store
+	level slog.Leveler
+}
+
+// New returns a Capture that records everything at level or above and reports to t.
+func New(t testing.TB, level slog.Leveler) *Capture {
+	return &Capture{t: t, store: &store{}, level: level}
+}
+
+// WithT returns a view of the capture that reports failures to t. The view shares
+// the records of c.
+func (c *Capture) WithT(t testing.TB) *Capture {
+	view := *c
+	view.t = t
+	return &view
+}
+
+// Handler returns a slog.Handler that records into c.
+func (c *Capture) Handler() *Handler {
+	return &Handler{store: c.store, level: c.level}
+}
+
+// Logger returns a logger that records into c.
+func (c *Capture) Logger() *slog.Logger {
+	return slog.New(c.Handler())
+}
+
+// Records returns a copy of the records captured so far.
+func (c *Capture) Records() []Record {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	return append([]Record(nil), c.store.records...)
+}
+
+// Reset discards the records captured so far.
+func (c *Capture) Reset() {
+	c.store.mu.Lock()
+	c.store.records = nil
+	c.store.mu.Unlock()
+}
+
+// Find returns the records at level whose message contains msg and which carry
+// attrs. Attributes are given as for slog.Logger.Log, key-value pairs or slog.Attr,
+// with dotted keys for attributes inside groups. Values of different kinds match if
+// they format the same, so an error attribute matches its message.
+func (c *Capture) Find(level slog.Level, msg string, attrs ...any) []Record {
+	want := argsToAttrs(attrs)
+	var found []Record
+	for _, r := range c.Records() {
+		if r.Level == level && strings.Contains(r.Message, msg) && hasAttrs(r, want) {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// ExpectLog reports whether a matching record was captured, as Find, and fails the
+// test listing the captured records if not.
+func (c *Capture) ExpectLog(level slog.Level, msg string, attrs ...any) bool {
+	c.t.Helper()
+	if len(c.Find(level, msg, attrs...)) > 0 {
+		return true
+	}
+	want := Record{Level: level, Message: fmt.Sprintf("%q", msg), Attrs: argsToAttrs(attrs)}
+	c.t.Errorf("expected log record %s, captured:\n%s", want, c.Dump())
+	return false
+}
+
+// NoErrorsLogged reports whether no record at error level or above was captured,
+// and fails the test listing them otherwise.
+func (c *Capture) NoErrorsLogged() bool {
+	c.t.Helper()
+	var errs []string
+	for _, r := range c.Records() {
+		if r.Level >= slog.LevelError {
+			errs = append(errs, r.String())
+		}
+	}
+	if len(errs) == 0 {
+		return true
+	}
+	c.t.Errorf("expected no errors to be logged, got %d:\n%s", len(errs), strings.Join(errs, "\n"))
+	return false
+}
+
+// Dump formats every captured record, one per line.
+func (c *Capture) Dump() string {
+	records := c.Records()
+	if len(records) == 0 {
+		return "(no log records)"
+	}
+	lines := make([]string, len(records))
+	for i, r := range records {
+		lines[i] = r.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func argsToAttrs(args []any) []slog.Attr {
+	var r slog.Record
+	r.Add(args...)
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendFlat(attrs, "", a)
+		return true
+	})
+	return attrs
+}
+
+func hasAttrs(r Record, want []slog.Attr) bool {
+	for _, w := range want {
+		got, ok := r.Attr(w.Key)
+		if !ok || !valueEqual(got, w.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// valueEqual compares log values. Values of different kinds are equal if they format
+// the same, so an error attribute matches its message and "2" matches 2.
+func valueEqual(got, want slog.Value) bool {
+	if got.Kind() != want.Kind() {
+		return got.String() == want.String()
+	}
+	if got.Kind() == slog.KindAny {
+		return reflect.DeepEqual(got.Any(), want.Any())
+	}
+	return got.Equal(want)
+}
@@ -0,0 +1,123 @@
+package logcapture
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type errorfTB struct {
+	testing.TB
+	errors []string
+}
+
+func (m *errorfTB) Errorf(format string, args ...any) {
+	m.errors = append(m.errors, fmt.Sprintf(format, args...))
+}
+
+func TestHandler(t *testing.T) {
+	c := New(t, slog.LevelInfo)
+	logger := c.Logger().With("service", "billing").WithGroup("req")
+	logger.Debug("dropped")
+	logger.Info("handled", "id", 7, slog.Group("user", "name", "ada"), slog.Group("", "inline", true))
+
+	records := c.Records()
+	if len(records) != 1 {
+		t.Fatalf("expected debug record to be filtered, got %v", records)
+	}
+	var keys []string
+	for _, a := range records[0].Attrs {
+		keys = append(keys, a.Key)
+	}
+	if got, want := strings.Join(keys, ","), "service,req.id,req.user.name,req.inline"; got != want {
+		t.Errorf("expected flattened keys %s, got %s", want, got)
+	}
+	if got := records[0].String(); !strings.HasSuffix(got, "INFO handled service=billing req.id=7 req.user.name=ada req.inline=true") {
+		t.Errorf("unexpected formatting %q", got)
+	}
+
+	c.Reset()
+	if len(c.Records()) != 0 {
+		t.Error("expected Reset to discard records")
+	}
+}
+
+func TestHandler_Concurrent(t *testing.T) {
+	c := New(t, slog.LevelDebug)
+	logger := c.Logger()
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() { logger.Info("work", "worker", i) })
+	}
+	wg.Wait()
+	if n := len(c.Records()); n != 10 {
+		t.Errorf("expected 10 records, got %d", n)
+	}
+}
+
+func TestCapture_ExpectLog(t *testing.T) {
+	mtb := &errorfTB{TB: t}
+	c := New(mtb, slog.LevelDebug)
+	logger := c.Logger()
+	logger.Warn("retrying upstream call", "attempt", 2, "err", errors.New("connection refused"))
+
+	if !c.ExpectLog(slog.LevelWarn, "retrying", "attempt", 2) {
+		t.Error("expected matching record to be found")
+	}
+	if !c.ExpectLog(slog.LevelWarn, "upstream", slog.String("err", "connection refused"), "attempt", "2") {
+		t.Error("expected values to match by their formatting across kinds")
+	}
+	if len(mtb.errors) != 0 {
+		t.Fatalf("unexpected failures: %v", mtb.errors)
+	}
+
+	if c.ExpectLog(slog.LevelWarn, "retrying", "attempt", 3) || c.ExpectLog(slog.LevelError, "retrying") {
+		t.Error("expected mismatched attrs and level not to match")
+	}
+	if len(mtb.errors) != 2 || !strings.Contains(mtb.errors[0], `WARN "retrying" attempt=3`) ||
+		!strings.Contains(mtb.errors[0], "WARN retrying upstream call attempt=2") {
+		t.Errorf("expected failure to show the wanted and captured records, got %v", mtb.errors)
+	}
+}
+
+func TestCapture_NoErrorsLogged(t *testing.T) {
+	mtb := &errorfTB{TB: t}
+	c := New(mtb, slog.LevelDebug)
+	c.Logger().Warn("slow")
+	if !c.NoErrorsLogged() {
+		t.Error("expected warnings not to count as errors")
+	}
+
+	c.Logger().Error("write failed", "table", "orders")
+	if c.NoErrorsLogged() || len(mtb.errors) != 1 || !strings.Contains(mtb.errors[0], "ERROR write failed table=orders") {
+		t.Errorf("expected error record to be reported, got %v", mtb.errors)
+	}
+}
+
+func TestCapture_WithT(t *testing.T) {
+	c := New(t, slog.LevelDebug)
+	mtb := &errorfTB{TB: t}
+	view := c.WithT(mtb)
+	c.Logger().Error("boom")
+
+	view.NoErrorsLogged()
+	if len(mtb.errors) != 1 {
+		t.Errorf("expected view to share records and report to its own test, got %v", mtb.errors)
+	}
+}
+
+func TestCapture_Dump(t *testing.T) {
+	c := New(t, slog.LevelDebug)
+	if got := c.Dump(); got != "(no log records)" {
+		t.Errorf("unexpected empty dump %q", got)
+	}
+	c.Logger().Info("a", "msg", "with space")
+	c.Logger().Info("b")
+	lines := strings.Split(c.Dump(), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], `INFO a msg="with space"`) {
+		t.Errorf("unexpected dump %q", lines)
+	}
+}
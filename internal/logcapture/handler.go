@@ -0,0 +1,88 @@
+// Package logcapture provides a slog handler that buffers records for assertions
+// and for dumping when a test fails.
+package logcapture
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// store holds the records of a Capture and every handler derived from it.
+type store struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+// Handler is a slog.Handler that appends every record to its Capture. Attributes
+// inside groups are flattened to dotted keys, e.g. "request.id".
+type Handler struct {
+	store  *store
+	level  slog.Leveler
+	attrs  []slog.Attr // already qualified with the groups in effect when added
+	prefix string      // groups opened with WithGroup, joined and dot-terminated
+}
+
+// Enabled reports whether records at level are captured.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle captures r.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	attrs := slices.Clip(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendFlat(attrs, h.prefix, a)
+		return true
+	})
+	h.store.mu.Lock()
+	h.store.records = append(h.store.records, Record{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Attrs:   attrs,
+	})
+	h.store.mu.Unlock()
+	return nil
+}
+
+// WithAttrs returns a handler that adds attrs to every record.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = slices.Clip(h.attrs)
+	for _, a := range attrs {
+		clone.attrs = appendFlat(clone.attrs, h.prefix, a)
+	}
+	return &clone
+}
+
+// WithGroup returns a handler that qualifies later attributes with name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// appendFlat appends a, resolved and with groups flattened, following the rules of
+// the built-in handlers: empty attributes are dropped and groups without a key are
+// inlined.
+func appendFlat(attrs []slog.Attr, prefix string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		return append(attrs, slog.Attr{Key: prefix + a.Key, Value: a.Value})
+	}
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, member := range a.Value.Group() {
+		attrs = appendFlat(attrs, prefix, member)
+	}
+	return attrs
+}
@@ -0,0 +1,121 @@
+// Package probe provides readiness probes and the retry loop used to wait for them.
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Func reports whether a resource is ready; a nil error means ready.
+type Func func(ctx context.Context) error
+
+// Backoff controls the delay between probe attempts.
+type Backoff struct {
+	// Initial is the delay after the first failed attempt.
+	Initial time.Duration
+	// Max caps the delay; it doubles after each failed attempt until it reaches Max.
+	Max time.Duration
+}
+
+// DefaultBackoff is used when a Backoff has no Initial delay.
+var DefaultBackoff = Backoff{Initial: 50 * time.Millisecond, Max: time.Second}
+
+// DefaultTimeout is the overall deadline used when Wait is given no timeout.
+const DefaultTimeout = 30 * time.Second
+
+// NotReadyError is returned by Wait when the probe never succeeded before the deadline.
+type NotReadyError struct {
+	Attempts int
+	Elapsed  time.Duration
+	Last     error
+}
+
+func (e *NotReadyError) Error() string {
+	return fmt.Sprintf("not ready after %s (%d attempts): %v", e.Elapsed.Round(time.Millisecond), e.Attempts, e.Last)
+}
+
+func (e *NotReadyError) Unwrap() error {
+	return e.Last
+}
+
+// Wait calls probe until it succeeds, ctx is cancelled, or timeout elapses.
+func Wait(ctx context.Context, probe Func, backoff Backoff, timeout time.Duration) error {
+	if backoff.Initial <= 0 {
+		backoff = DefaultBackoff
+	}
+	if backoff.Max < backoff.Initial {
+		backoff.Max = backoff.Initial
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	delay := backoff.Initial
+	attempts := 0
+	for {
+		attempts++
+		err := probe(ctx)
+		if err == nil {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+				err = fmt.Errorf("%w (probe did not return before deadline)", err)
+			}
+			return &NotReadyError{Attempts: attempts, Elapsed: time.Since(start), Last: err}
+		case <-timer.C:
+		}
+		delay = min(delay*2, backoff.Max)
+	}
+}
+
+// TCP returns a probe that succeeds once addr accepts TCP connections.
+func TCP(addr string) Func {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// HTTP returns a probe that succeeds once a GET to url answers with one of statuses,
+// or with any 2xx status if none are given.
+func HTTP(client *http.Client, url string, statuses ...int) Func {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+
+		ok := resp.StatusCode >= 200 && resp.StatusCode < 300
+		if len(statuses) > 0 {
+			ok = slices.Contains(statuses, resp.StatusCode)
+		}
+		if !ok {
+			return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,77 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	fast := Backoff{Initial: time.Millisecond, Max: 2 * time.Millisecond}
+
+	t.Run("EventuallyReady", func(t *testing.T) {
+		calls := 0
+		err := Wait(context.Background(), func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		}, fast, time.Second)
+		if err != nil || calls != 3 {
+			t.Errorf("expected success on third attempt, got err=%v calls=%d", err, calls)
+		}
+	})
+
+	t.Run("NeverReady", func(t *testing.T) {
+		err := Wait(context.Background(), func(context.Context) error {
+			return errors.New("connection refused")
+		}, fast, 20*time.Millisecond)
+
+		var notReady *NotReadyError
+		if !errors.As(err, &notReady) {
+			t.Fatalf("expected NotReadyError, got %v", err)
+		}
+		if notReady.Attempts < 2 || !strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("unexpected diagnostic: %v", err)
+		}
+	})
+}
+
+func TestProbes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	if err := TCP(srv.Listener.Addr().String())(ctx); err != nil {
+		t.Errorf("expected TCP probe to succeed, got %v", err)
+	}
+	if err := HTTP(srv.Client(), srv.URL+"/up")(ctx); err != nil {
+		t.Errorf("expected HTTP probe to succeed, got %v", err)
+	}
+	if err := HTTP(srv.Client(), srv.URL+"/down")(ctx); err == nil {
+		t.Error("expected HTTP probe to fail on 503")
+	}
+	if err := HTTP(srv.Client(), srv.URL+"/down", http.StatusServiceUnavailable)(ctx); err != nil {
+		t.Errorf("expected HTTP probe to accept listed status, got %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	if err := TCP(addr)(ctx); err == nil {
+		t.Error("expected TCP probe to fail on a closed port")
+	}
+}
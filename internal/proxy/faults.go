@@ -0,0 +1,86 @@
+// Package proxy provides in-process TCP and HTTP proxies that inject faults such as
+// latency, bandwidth limits, connection resets, truncated responses and error
+// statuses into the traffic they forward. Faults can be changed at any time and
+// apply to data forwarded afterwards.
+package proxy
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// faults holds the faults shared by both proxy kinds. Latency, bandwidth and
+// truncation apply to the downstream direction, from the target to the client.
+type faults struct {
+	mu        sync.Mutex
+	latency   time.Duration
+	jitter    time.Duration
+	bandwidth int // bytes per second, 0 for unlimited
+	truncate  int // bytes per connection or response, negative for none
+	reset     bool
+}
+
+func newFaults() *faults {
+	return &faults{truncate: -1}
+}
+
+// snapshot is a consistent copy of the faults for a single forwarding step.
+type snapshot struct {
+	latency   time.Duration
+	bandwidth int
+	truncate  int
+	reset     bool
+}
+
+func (f *faults) snapshot() snapshot {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := snapshot{latency: f.latency, bandwidth: f.bandwidth, truncate: f.truncate, reset: f.reset}
+	if f.jitter > 0 {
+		s.latency += time.Duration(rand.Int64N(int64(2*f.jitter+1))) - f.jitter
+		s.latency = max(s.latency, 0)
+	}
+	return s
+}
+
+// Latency delays forwarded data by d, varied uniformly by up to ±jitter.
+func (f *faults) Latency(d, jitter time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency, f.jitter = d, jitter
+}
+
+// Bandwidth limits forwarded data to bytesPerSecond; 0 removes the limit.
+func (f *faults) Bandwidth(bytesPerSecond int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bandwidth = bytesPerSecond
+}
+
+// Truncate closes the connection after n bytes have been forwarded to the client.
+func (f *faults) Truncate(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.truncate = n
+}
+
+func (f *faults) setReset(reset bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reset = reset
+}
+
+func (f *faults) heal() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency, f.jitter, f.bandwidth, f.truncate, f.reset = 0, 0, 0, -1, false
+}
+
+// throttle returns how long writing n bytes takes at bandwidth.
+func throttle(n, bandwidth int) time.Duration {
+	if bandwidth <= 0 {
+		return 0
+	}
+	return time.Duration(n) * time.Second / time.Duration(bandwidth)
+}
@@ -0,0 +1,168 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// errTruncated aborts a response once the truncation limit is reached.
+var errTruncated = errors.New("response truncated by proxy")
+
+// HTTP is a reverse proxy forwarding requests to a target base URL. Faults apply
+// per request: latency delays the response, truncation and bandwidth apply to each
+// response body, and Reset drops connections without a response.
+type HTTP struct {
+	*faults
+	target *url.URL
+	server *httptest.Server
+
+	mu         sync.Mutex
+	status     int
+	statusLeft int // requests still answered with status, negative for all
+}
+
+// NewHTTP starts a proxy on a loopback port that forwards to target, e.g. the base
+// URL of an HTTP server. Targets using HTTPS must be trusted by transport, which
+// defaults to http.DefaultTransport.
+func NewHTTP(target string, transport http.RoundTripper) (*HTTP, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target %q: %w", target, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid target %q: want an absolute URL", target)
+	}
+	p := &HTTP{faults: newFaults(), target: u}
+	rp := httputil.NewSingleHostReverseProxy(u)
+	rp.Transport = transport
+	rp.FlushInterval = -1
+	rp.ErrorLog = log.New(io.Discard, "", 0)
+	p.server = httptest.NewServer(p.handler(rp))
+	return p, nil
+}
+
+// URL returns the base URL clients should use instead of the target.
+func (p *HTTP) URL() string {
+	return p.server.URL
+}
+
+// Target returns the URL the proxy forwards to.
+func (p *HTTP) Target() string {
+	return p.target.String()
+}
+
+// Status answers every request with code instead of forwarding it.
+func (p *HTTP) Status(code int) {
+	p.StatusTimes(code, -1)
+}
+
+// StatusTimes answers the next n requests with code, then forwards again.
+func (p *HTTP) StatusTimes(code, n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status, p.statusLeft = code, n
+}
+
+// Reset drops every request's connection without a response until Heal is called.
+func (p *HTTP) Reset() {
+	p.setReset(true)
+}
+
+// Heal removes all faults.
+func (p *HTTP) Heal() {
+	p.heal()
+	p.StatusTimes(0, 0)
+}
+
+// Close stops the proxy.
+func (p *HTTP) Close() error {
+	p.server.CloseClientConnections()
+	p.server.Close()
+	return nil
+}
+
+// injectedStatus returns the status to answer with, if any, consuming one use.
+func (p *HTTP) injectedStatus() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.status == 0 || p.statusLeft == 0 {
+		return 0
+	}
+	if p.statusLeft > 0 {
+		p.statusLeft--
+	}
+	return p.status
+}
+
+func (p *HTTP) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := p.snapshot()
+		if f.reset {
+			if hj, ok := w.(http.Hijacker); ok {
+				if conn, _, err := hj.Hijack(); err == nil {
+					reset(conn)
+					return
+				}
+			}
+			panic(http.ErrAbortHandler)
+		}
+		time.Sleep(f.latency)
+		if code := p.injectedStatus(); code != 0 {
+			http.Error(w, fmt.Sprintf("fault injected by proxy: %d %s", code, http.StatusText(code)), code)
+			return
+		}
+		if f.truncate < 0 && f.bandwidth <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(&faultWriter{ResponseWriter: w, fault: f}, r)
+	})
+}
+
+// faultWriter applies bandwidth limits and truncation to a response body. When the
+// truncation limit is hit it returns an error, which makes the reverse proxy abort
+// the connection after the bytes already written.
+type faultWriter struct {
+	http.ResponseWriter
+	fault   snapshot
+	written int
+}
+
+func (w *faultWriter) Write(b []byte) (int, error) {
+	total := 0
+	for len(b) > 0 {
+		chunk := b[:min(len(b), chunkSize)]
+		truncated := w.fault.truncate >= 0 && w.written+len(chunk) >= w.fault.truncate
+		if truncated {
+			chunk = chunk[:max(w.fault.truncate-w.written, 0)]
+		}
+		time.Sleep(throttle(len(chunk), w.fault.bandwidth))
+		n, err := w.ResponseWriter.Write(chunk)
+		total += n
+		w.written += n
+		if err != nil {
+			return total, err
+		}
+		if truncated {
+			w.Flush()
+			return total, errTruncated
+		}
+		w.Flush()
+		b = b[len(chunk):]
+	}
+	return total, nil
+}
+
+func (w *faultWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,208 @@
+package proxy
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const payload = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// startEcho starts a TCP server that answers every line with payload.
+func startEcho(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				buf := make([]byte, 64)
+				for {
+					if _, err := conn.Read(buf); err != nil {
+						return
+					}
+					if _, err := conn.Write([]byte(payload)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// roundTrip sends a line through addr and reads until the connection closes or
+// len(payload) bytes arrived.
+func roundTrip(t *testing.T, addr string) (string, time.Duration, error) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = conn.Close() }()
+	start := time.Now()
+	if _, err := conn.Write([]byte("ping\n")); err != nil {
+		return "", 0, err
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, len(payload))
+	n, err := io.ReadFull(conn, buf)
+	return string(buf[:n]), time.Since(start), err
+}
+
+func TestTCP(t *testing.T) {
+	p, err := NewTCP(startEcho(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+
+	if got, _, err := roundTrip(t, p.Addr()); err != nil || got != payload {
+		t.Fatalf("expected data to be forwarded, got %q (%v)", got, err)
+	}
+
+	t.Run("Latency", func(t *testing.T) {
+		p.Latency(50*time.Millisecond, 10*time.Millisecond)
+		defer p.Heal()
+		if _, d, err := roundTrip(t, p.Addr()); err != nil || d < 40*time.Millisecond {
+			t.Errorf("expected latency, took %v (%v)", d, err)
+		}
+	})
+
+	t.Run("Bandwidth", func(t *testing.T) {
+		p.Bandwidth(len(payload) * 10) // 100ms for the payload
+		defer p.Heal()
+		if _, d, err := roundTrip(t, p.Addr()); err != nil || d < 80*time.Millisecond {
+			t.Errorf("expected throttled transfer, took %v (%v)", d, err)
+		}
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		p.Truncate(10)
+		defer p.Heal()
+		got, _, err := roundTrip(t, p.Addr())
+		if got != payload[:10] || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("expected truncated data, got %q (%v)", got, err)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		conn, err := net.Dial("tcp", p.Addr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = conn.Write([]byte("ping\n"))
+		_, _ = io.ReadFull(conn, make([]byte, len(payload)))
+
+		p.Reset()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.Read(make([]byte, 1)); !errors.Is(err, syscall.ECONNRESET) && !errors.Is(err, io.EOF) {
+			t.Errorf("expected open connection to be reset, got %v", err)
+		}
+		if _, _, err := roundTrip(t, p.Addr()); err == nil {
+			t.Error("expected new connections to be reset")
+		}
+
+		p.Heal()
+		if got, _, err := roundTrip(t, p.Addr()); err != nil || got != payload {
+			t.Errorf("expected healed proxy to forward, got %q (%v)", got, err)
+		}
+	})
+}
+
+func TestHTTP(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(payload + r.URL.Path))
+	}))
+	defer upstream.Close()
+	p, err := NewHTTP(upstream.URL, upstream.Client().Transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = p.Close() }()
+
+	get := func(t *testing.T) (int, string, time.Duration, error) {
+		t.Helper()
+		start := time.Now()
+		resp, err := http.Get(p.URL() + "/x")
+		if err != nil {
+			return 0, "", 0, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body), time.Since(start), err
+	}
+
+	if code, body, _, err := get(t); err != nil || code != http.StatusOK || body != payload+"/x" {
+		t.Fatalf("expected request to be forwarded, got %d %q (%v)", code, body, err)
+	}
+
+	t.Run("Status", func(t *testing.T) {
+		p.StatusTimes(http.StatusServiceUnavailable, 2)
+		defer p.Heal()
+		var codes []int
+		for range 3 {
+			code, _, _, _ := get(t)
+			codes = append(codes, code)
+		}
+		if codes[0] != 503 || codes[1] != 503 || codes[2] != 200 {
+			t.Errorf("expected two injected failures, got %v", codes)
+		}
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		p.Latency(50*time.Millisecond, 0)
+		defer p.Heal()
+		if _, _, d, err := get(t); err != nil || d < 50*time.Millisecond {
+			t.Errorf("expected latency, took %v (%v)", d, err)
+		}
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		p.Truncate(10)
+		defer p.Heal()
+		_, body, _, err := get(t)
+		if body != payload[:10] || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("expected truncated body, got %q (%v)", body, err)
+		}
+	})
+
+	t.Run("Bandwidth", func(t *testing.T) {
+		p.Bandwidth(len(payload) * 10)
+		defer p.Heal()
+		if _, body, d, err := get(t); err != nil || body != payload+"/x" || d < 80*time.Millisecond {
+			t.Errorf("expected throttled body, got %q in %v (%v)", body, d, err)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		p.Reset()
+		_, _, _, err := get(t)
+		if err == nil || !strings.Contains(err.Error(), "EOF") && !errors.Is(err, syscall.ECONNRESET) {
+			t.Errorf("expected connection to be dropped, got %v", err)
+		}
+		p.Heal()
+		if _, _, _, err := get(t); err != nil {
+			t.Errorf("expected healed proxy to forward, got %v", err)
+		}
+	})
+
+	if _, err := NewHTTP("localhost:80", nil); err == nil {
+		t.Error("expected relative target to be rejected")
+	}
+}
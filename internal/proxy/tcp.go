@@ -0,0 +1,190 @@
+package proxy
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// dialTimeout bounds connecting to the target.
+const dialTimeout = 5 * time.Second
+
+// chunkSize bounds how much data is forwarded per step, so bandwidth limits and
+// truncation are applied with reasonable granularity.
+const chunkSize = 4 << 10
+
+// TCP is a TCP proxy forwarding connections to a target address.
+type TCP struct {
+	*faults
+	target   string
+	listener net.Listener
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewTCP starts a proxy on a loopback port that forwards to target ("host:port").
+func NewTCP(target string) (*TCP, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	p := &TCP{faults: newFaults(), target: target, listener: ln, conns: make(map[net.Conn]struct{})}
+	p.wg.Add(1)
+	go p.accept()
+	return p, nil
+}
+
+// Addr returns the address clients should connect to instead of the target.
+func (p *TCP) Addr() string {
+	return p.listener.Addr().String()
+}
+
+// Target returns the address the proxy forwards to.
+func (p *TCP) Target() string {
+	return p.target
+}
+
+// Reset resets open connections and every new one until Heal is called.
+func (p *TCP) Reset() {
+	p.setReset(true)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for conn := range p.conns {
+		reset(conn)
+	}
+}
+
+// Heal removes all faults.
+func (p *TCP) Heal() {
+	p.heal()
+}
+
+// Close stops accepting connections and closes the open ones.
+func (p *TCP) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	for conn := range p.conns {
+		_ = conn.Close()
+	}
+	p.mu.Unlock()
+	err := p.listener.Close()
+	p.wg.Wait()
+	return err
+}
+
+func (p *TCP) accept() {
+	defer p.wg.Done()
+	for {
+		client, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		if p.snapshot().reset {
+			reset(client)
+			continue
+		}
+		p.wg.Add(1)
+		go p.serve(client)
+	}
+}
+
+func (p *TCP) serve(client net.Conn) {
+	defer p.wg.Done()
+	upstream, err := net.DialTimeout("tcp", p.target, dialTimeout)
+	if err != nil {
+		reset(client)
+		return
+	}
+	if !p.track(client, upstream) {
+		_ = client.Close()
+		_ = upstream.Close()
+		return
+	}
+	defer p.untrack(client, upstream)
+
+	var once sync.Once
+	closeBoth := func() {
+		once.Do(func() {
+			_ = client.Close()
+			_ = upstream.Close()
+		})
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(upstream, client)
+		closeBoth()
+	}()
+	p.downstream(client, upstream, closeBoth)
+	closeBoth()
+	<-done
+}
+
+// downstream forwards from upstream to client, applying the current faults.
+func (p *TCP) downstream(client, upstream net.Conn, closeBoth func()) {
+	buf := make([]byte, chunkSize)
+	written := 0
+	for {
+		n, err := upstream.Read(buf)
+		if n > 0 {
+			f := p.snapshot()
+			if f.reset {
+				reset(client)
+				closeBoth()
+				return
+			}
+			time.Sleep(f.latency + throttle(n, f.bandwidth))
+			chunk := buf[:n]
+			truncated := f.truncate >= 0 && written+n >= f.truncate
+			if truncated {
+				chunk = chunk[:max(f.truncate-written, 0)]
+			}
+			if _, werr := client.Write(chunk); werr != nil {
+				return
+			}
+			written += len(chunk)
+			if truncated {
+				return
+			}
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				reset(client)
+			}
+			return
+		}
+	}
+}
+
+func (p *TCP) track(conns ...net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	for _, c := range conns {
+		p.conns[c] = struct{}{}
+	}
+	return true
+}
+
+func (p *TCP) untrack(conns ...net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range conns {
+		delete(p.conns, c)
+	}
+}
+
+// reset closes conn with an RST instead of an orderly FIN.
+func reset(conn net.Conn) {
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = conn.Close()
+}
@@ -0,0 +1,165 @@
+// Package random provides a seeded source of fake test data.
+package random
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SeedEnv overrides the seed of every source when set to an unsigned integer.
+const SeedEnv = "TESTKIT_SEED"
+
+var (
+	firstNames = []string{
+		"Ada", "Alan", "Barbara", "Claude", "Dennis", "Edsger", "Frances", "Grace",
+		"Hedy", "Ivan", "Jean", "Ken", "Leslie", "Margaret", "Niklaus", "Radia",
+	}
+	lastNames = []string{
+		"Allen", "Backus", "Cerf", "Dijkstra", "Hamilton", "Hopper", "Kay", "Knuth",
+		"Lamport", "Liskov", "Lovelace", "Perlman", "Ritchie", "Shannon", "Thompson", "Wirth",
+	}
+	words = []string{
+		"alpha", "amber", "bridge", "cedar", "delta", "ember", "falcon", "garden",
+		"harbor", "island", "juniper", "lantern", "meadow", "nectar", "orbit", "prairie",
+		"quartz", "river", "summit", "timber", "umber", "valley", "willow", "zephyr",
+	}
+)
+
+const letters = "abcdefghijklmnopqrstuvwxyz"
+
+// Rand is a deterministic source of fake data. It is safe for concurrent use, but
+// only sequential use yields the same values for the same seed.
+type Rand struct {
+	mu   sync.Mutex
+	seed uint64
+	rand *rand.Rand
+	seqs map[any]int
+}
+
+// New returns a source seeded with seed.
+func New(seed uint64) *Rand {
+	return &Rand{
+		seed: seed,
+		rand: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+		seqs: make(map[any]int),
+	}
+}
+
+// SeedFor derives a seed from name, typically the test name, so each test gets its
+// own stable sequence.
+func SeedFor(name string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return h.Sum64()
+}
+
+// Seed returns the seed the source was created with.
+func (r *Rand) Seed() uint64 {
+	return r.seed
+}
+
+// Next returns the next number, starting at 1, of the sequence identified by key.
+func (r *Rand) Next(key any) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seqs[key]++
+	return r.seqs[key]
+}
+
+// IntN returns a number in [0, n). It panics if n <= 0.
+func (r *Rand) IntN(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.IntN(n)
+}
+
+// IntBetween returns a number in [lo, hi].
+func (r *Rand) IntBetween(lo, hi int) int {
+	return lo + r.IntN(hi-lo+1)
+}
+
+// Float64 returns a number in [0.0, 1.0).
+func (r *Rand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Float64()
+}
+
+// Bool returns true or false with equal probability.
+func (r *Rand) Bool() bool {
+	return r.IntN(2) == 1
+}
+
+// Letters returns n random lowercase ASCII letters.
+func (r *Rand) Letters(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[r.IntN(len(letters))]
+	}
+	return string(b)
+}
+
+// Word returns a random lowercase word.
+func (r *Rand) Word() string {
+	return Pick(r, words)
+}
+
+// FirstName returns a random first name.
+func (r *Rand) FirstName() string {
+	return Pick(r, firstNames)
+}
+
+// LastName returns a random last name.
+func (r *Rand) LastName() string {
+	return Pick(r, lastNames)
+}
+
+// Name returns a random full name.
+func (r *Rand) Name() string {
+	return r.FirstName() + " " + r.LastName()
+}
+
+// Email returns a random address under example.com. Addresses from one source are unique.
+func (r *Rand) Email() string {
+	return fmt.Sprintf("%s.%s%d@example.com",
+		strings.ToLower(r.FirstName()), strings.ToLower(r.LastName()), r.Next(&emailSeq))
+}
+
+var emailSeq byte // its address keys the Email sequence
+
+// UUID returns a random version 4 UUID in canonical form.
+func (r *Rand) UUID() string {
+	r.mu.Lock()
+	var b [16]byte
+	for i := 0; i < len(b); i += 8 {
+		v := r.rand.Uint64()
+		for j := range 8 {
+			b[i+j] = byte(v >> (8 * j))
+		}
+	}
+	r.mu.Unlock()
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Time returns a random time in [from, to).
+func (r *Rand) Time(from, to time.Time) time.Time {
+	span := to.Sub(from)
+	if span <= 0 {
+		return from
+	}
+	r.mu.Lock()
+	offset := time.Duration(r.rand.Int64N(int64(span)))
+	r.mu.Unlock()
+	return from.Add(offset)
+}
+
+// Pick returns a random element of items. It panics if items is empty.
+func Pick[T any](r *Rand, items []T) T {
+	return items[r.IntN(len(items))]
+}
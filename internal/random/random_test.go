@@ -0,0 +1,74 @@
+package random
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestRand_Deterministic(t *testing.T) {
+	draw := func(r *Rand) []any {
+		return []any{r.Name(), r.Email(), r.UUID(), r.IntBetween(1, 6), r.Letters(8), r.Word(), r.Bool(), r.Float64()}
+	}
+	a, b := draw(New(42)), draw(New(42))
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("expected equal seeds to yield equal values, got %v and %v", a, b)
+			break
+		}
+	}
+	if c := draw(New(43)); c[2] == a[2] {
+		t.Error("expected different seeds to yield different UUIDs")
+	}
+}
+
+func TestSeedFor(t *testing.T) {
+	if SeedFor("TestA") != SeedFor("TestA") || SeedFor("TestA") == SeedFor("TestB") {
+		t.Error("expected seeds to be stable per name and differ across names")
+	}
+}
+
+func TestRand_Values(t *testing.T) {
+	r := New(SeedFor(t.Name()))
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for range 20 {
+		if id := r.UUID(); !uuid.MatchString(id) {
+			t.Fatalf("expected version 4 UUID, got %s", id)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for range 50 {
+		email := r.Email()
+		if seen[email] {
+			t.Fatalf("expected unique emails, got %s twice", email)
+		}
+		seen[email] = true
+	}
+
+	for range 50 {
+		if n := r.IntBetween(3, 5); n < 3 || n > 5 {
+			t.Fatalf("expected value in [3, 5], got %d", n)
+		}
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	for range 50 {
+		if ts := r.Time(from, to); ts.Before(from) || !ts.Before(to) {
+			t.Fatalf("expected time in range, got %v", ts)
+		}
+	}
+	if ts := r.Time(to, from); !ts.Equal(to) {
+		t.Errorf("expected empty range to return from, got %v", ts)
+	}
+}
+
+func TestRand_Next(t *testing.T) {
+	r := New(1)
+	a, b := new(int), new(int)
+	if r.Next(a) != 1 || r.Next(a) != 2 || r.Next(b) != 1 {
+		t.Error("expected independent sequences starting at 1")
+	}
+}
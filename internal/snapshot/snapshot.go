@@ -0,0 +1,254 @@
+// Package snapshot compares test output against golden files stored under
+// testdata/snapshots, keyed by test name.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/next-trace/scg-test-kit/internal/diff"
+)
+
+// Dir is the directory snapshots are stored in, relative to the package under test.
+const Dir = "testdata/snapshots"
+
+// UpdateEnv regenerates snapshots when set to a true value. A boolean -update flag
+// defined by the test binary has the same effect.
+const UpdateEnv = "TESTKIT_UPDATE_SNAPSHOTS"
+
+// redactedField replaces the values of fields scrubbed with Fields.
+const redactedField = "<redacted>"
+
+// Option configures a snapshot comparison.
+type Option func(*config)
+
+type config struct {
+	name      string
+	fields    map[string]bool
+	redactors []func(string) string
+}
+
+// Name distinguishes several snapshots of the same test. Without it, snapshots are
+// numbered in the order they are taken.
+func Name(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// Redact rewrites the normalised output before it is compared or written.
+func Redact(fn func(string) string) Option {
+	return func(c *config) {
+		c.redactors = append(c.redactors, fn)
+	}
+}
+
+// Regexp replaces every match of re with replacement.
+func Regexp(re *regexp.Regexp, replacement string) Option {
+	return Redact(func(s string) string { return re.ReplaceAllString(s, replacement) })
+}
+
+var (
+	uuidPattern      = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	timestampPattern = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?\b`)
+)
+
+// UUIDs replaces UUIDs with <uuid>.
+func UUIDs() Option {
+	return Regexp(uuidPattern, "<uuid>")
+}
+
+// Timestamps replaces RFC 3339 style timestamps with <timestamp>.
+func Timestamps() Option {
+	return Regexp(timestampPattern, "<timestamp>")
+}
+
+// Fields replaces the values of the named JSON object keys, at any depth, with
+// "<redacted>". It has no effect on text snapshots.
+func Fields(names ...string) Option {
+	return func(c *config) {
+		if c.fields == nil {
+			c.fields = make(map[string]bool)
+		}
+		for _, name := range names {
+			c.fields[name] = true
+		}
+	}
+}
+
+// counters numbers the unnamed snapshots of each test.
+var counters sync.Map // testing.TB -> *int
+
+// Match compares value with the snapshot of the current test, reporting a unified
+// diff on mismatch. Strings and byte slices are compared as text; json.RawMessage
+// and other values are compared as indented JSON with sorted keys. Missing
+// snapshots are written, except when the CI environment variable is set.
+func Match(t testing.TB, value any, opts ...Option) {
+	t.Helper()
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	got, err := normalize(value, cfg.fields)
+	if err != nil {
+		t.Errorf("snapshot: %v", err)
+		return
+	}
+	for _, redact := range cfg.redactors {
+		got = redact(got)
+	}
+
+	path := filepath.Join(Dir, fileName(t, cfg.name))
+	want, err := os.ReadFile(path)
+	switch {
+	case update():
+		if string(want) != got {
+			write(t, path, got)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		if os.Getenv("CI") != "" {
+			t.Errorf("snapshot %s does not exist; run the test with %s=1 to create it", path, UpdateEnv)
+			return
+		}
+		write(t, path, got)
+		t.Logf("snapshot %s created", path)
+	case err != nil:
+		t.Errorf("snapshot: %v", err)
+	case string(want) != got:
+		t.Errorf("snapshot %s does not match (set %s=1 to update):\n%s", path, UpdateEnv, diff.Unified("snapshot", "actual", string(want), got))
+	}
+}
+
+// normalize renders value as snapshot text ending in a newline.
+func normalize(value any, fields map[string]bool) (string, error) {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		raw, ok := v.(json.RawMessage)
+		if !ok {
+			var err error
+			if raw, err = json.Marshal(v); err != nil {
+				return "", fmt.Errorf("failed to marshal value: %w", err)
+			}
+		}
+		doc, err := decodeJSON(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid JSON: %w", err)
+		}
+		doc = redactFields(doc, fields)
+
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		// Maps are encoded with sorted keys
+		if err := enc.Encode(doc); err != nil {
+			return "", err
+		}
+		text = buf.String()
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text, nil
+}
+
+// decodeJSON is json.Unmarshal into any keeping numbers exact, so integers beyond
+// float64 precision, such as int64 ids, are written to snapshots unchanged.
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after top-level value")
+	}
+	return doc, nil
+}
+
+func redactFields(doc any, fields map[string]bool) any {
+	if len(fields) == 0 {
+		return doc
+	}
+	switch v := doc.(type) {
+	case map[string]any:
+		for key, val := range v {
+			if fields[key] {
+				v[key] = redactedField
+			} else {
+				v[key] = redactFields(val, fields)
+			}
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = redactFields(val, fields)
+		}
+	}
+	return doc
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fileName derives the snapshot file from the test name and name, or the number of
+// unnamed snapshots the test has taken.
+func fileName(t testing.TB, name string) string {
+	base := unsafeChars.ReplaceAllString(t.Name(), "_")
+	if name == "" {
+		n, loaded := counters.LoadOrStore(t, new(int))
+		if !loaded {
+			t.Cleanup(func() { counters.Delete(t) })
+		}
+		count := n.(*int)
+		*count++
+		if *count > 1 {
+			name = strconv.Itoa(*count)
+		}
+	}
+	if name != "" {
+		base += "__" + unsafeChars.ReplaceAllString(name, "_")
+	}
+	return base + ".snap"
+}
+
+func write(t testing.TB, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Errorf("snapshot: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Errorf("snapshot: %v", err)
+	}
+}
+
+// update reports whether snapshots should be regenerated.
+func update() bool {
+	if f := flag.Lookup("update"); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			if on, ok := getter.Get().(bool); ok && on {
+				return true
+			}
+		}
+	}
+	on, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+	return on
+}
@@ -0,0 +1,114 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type errorfTB struct {
+	testing.TB
+	errors []string
+}
+
+func (m *errorfTB) Errorf(format string, args ...any) {
+	m.errors = append(m.errors, fmt.Sprintf(format, args...))
+}
+
+func (m *errorfTB) Logf(string, ...any) {}
+
+func TestMatch(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("CI", "")
+	t.Setenv(UpdateEnv, "")
+
+	type item struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		CreatedAt string `json:"createdAt"`
+		Secret    string `json:"secret"`
+	}
+	value := map[string]any{
+		"items": []item{{ID: "5f2b6f0e-1c1d-4c4e-9b4c-0f0e0d0c0b0a", Name: "widget", CreatedAt: "2024-05-22T10:00:00Z", Secret: "s3"}},
+		"count": 1,
+	}
+	opts := []Option{UUIDs(), Timestamps(), Fields("secret")}
+
+	mockT := &errorfTB{TB: t}
+	Match(mockT, value, opts...)
+	Match(mockT, "plain text", Name("text"))
+	Match(mockT, json.RawMessage(`{"b":1,"a":2}`))
+	if len(mockT.errors) != 0 {
+		t.Fatalf("expected snapshots to be created, got %q", mockT.errors)
+	}
+
+	data, err := os.ReadFile(filepath.Join(Dir, "TestMatch.snap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "count": 1,
+  "items": [
+    {
+      "createdAt": "<timestamp>",
+      "id": "<uuid>",
+      "name": "widget",
+      "secret": "<redacted>"
+    }
+  ]
+}
+`
+	if string(data) != want {
+		t.Errorf("unexpected snapshot:\n%s", data)
+	}
+	for _, name := range []string{"TestMatch__text.snap", "TestMatch__2.snap"} {
+		if _, err := os.Stat(filepath.Join(Dir, name)); err != nil {
+			t.Errorf("expected snapshot %s: %v", name, err)
+		}
+	}
+
+	t.Run("Mismatch", func(t *testing.T) {
+		path := filepath.Join(Dir, "TestMatch_Mismatch.snap")
+		if err := os.WriteFile(path, []byte("line 1\nline 2\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mockT := &errorfTB{TB: t}
+		Match(mockT, "line 1\nline two")
+		if len(mockT.errors) != 1 || !strings.Contains(mockT.errors[0], "-line 2\n+line two") {
+			t.Fatalf("expected a diff, got %q", mockT.errors)
+		}
+
+		t.Setenv(UpdateEnv, "1")
+		mockT = &errorfTB{TB: t}
+		Match(mockT, "line 1\nline two")
+		if data, _ := os.ReadFile(path); len(mockT.errors) != 0 || string(data) != "line 1\nline two\n" {
+			t.Errorf("expected snapshot to be updated, got %q (%q)", data, mockT.errors)
+		}
+	})
+
+	t.Run("MissingOnCI", func(t *testing.T) {
+		t.Setenv("CI", "true")
+		mockT := &errorfTB{TB: t}
+		Match(mockT, "x")
+		if len(mockT.errors) != 1 || !strings.Contains(mockT.errors[0], "does not exist") {
+			t.Errorf("expected missing snapshot to fail on CI, got %q", mockT.errors)
+		}
+	})
+}
+
+func TestNormalize_LargeIntegers(t *testing.T) {
+	got, err := normalize(map[string]any{"id": int64(9007199254740993), "ratio": 0.5}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"id\": 9007199254740993,\n  \"ratio\": 0.5\n}\n"; got != want {
+		t.Errorf("expected integers beyond float64 precision to be kept, got:\n%s", got)
+	}
+
+	if _, err := normalize(json.RawMessage(`{"a":1} {}`), nil); err == nil {
+		t.Error("expected trailing data to be rejected")
+	}
+}
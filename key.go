@@ -0,0 +1,67 @@
+package testkit
+
+import (
+	"reflect"
+)
+
+// Key is a typed resource name. Declare it once and use it with WithKey, Lookup and
+// MustResource so that the resource type is checked at compile time:
+//
+//	var DBKey = testkit.NewKey[*sql.DB]("db")
+//
+//	h := testkit.New(t, testkit.WithKey(DBKey, db, db.Close))
+//	db := testkit.MustResource(h, DBKey)
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a Key for a resource of type T stored under name.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Name returns the resource name, e.g. for DependsOn.
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// String returns the resource name and type.
+func (k Key[T]) String() string {
+	return k.name + " (" + reflect.TypeFor[T]().String() + ")"
+}
+
+// WithKey adds a resource under key. It is the typed form of WithResource.
+func WithKey[T any](key Key[T], value T, cleanup func() error, opts ...ResourceOption) Option {
+	return WithResource(key.name, value, cleanup, opts...)
+}
+
+// WithKeyProvider adds a lazily constructed resource under key. It is the typed form of WithProvider.
+func WithKeyProvider[T any](key Key[T], provider func(*Harness) (T, func() error, error), opts ...ResourceOption) Option {
+	return WithProvider(key.name, provider, opts...)
+}
+
+// Lookup retrieves the resource stored under key. It is the typed form of Resource.
+func Lookup[T any](h *Harness, key Key[T]) (T, bool) {
+	h.T().Helper()
+	return Resource[T](h, key.name)
+}
+
+// MustResource retrieves the resource stored under key and fails the test if it is
+// missing or, because it was registered by name with another type, has the wrong type.
+func MustResource[T any](h *Harness, key Key[T]) T {
+	t := h.T()
+	t.Helper()
+
+	var zero T
+	val, ok := h.Resource(key.name)
+	if !ok {
+		t.Fatalf("resource %q is missing", key.name)
+		return zero
+	}
+	typed, ok := val.(T)
+	if !ok {
+		t.Fatalf("resource %q has wrong type: want %s, got %T", key.name, reflect.TypeFor[T](), val)
+		return zero
+	}
+	return typed
+}
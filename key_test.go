@@ -0,0 +1,58 @@
+package testkit
+
+import (
+	"net/http"
+	"testing"
+)
+
+var clientKey = NewKey[*http.Client]("client")
+
+func TestKey(t *testing.T) {
+	client := &http.Client{}
+
+	t.Run("WithKey", func(t *testing.T) {
+		h := New(t, WithKey(clientKey, client, nil))
+		got, ok := Lookup(h, clientKey)
+		if !ok || got != client {
+			t.Errorf("expected client, got %v (ok=%v)", got, ok)
+		}
+		if MustResource(h, clientKey) != client {
+			t.Error("expected MustResource to return client")
+		}
+	})
+
+	t.Run("WithKeyProvider", func(t *testing.T) {
+		h := New(t, WithKeyProvider(clientKey, func(*Harness) (*http.Client, func() error, error) {
+			return client, nil, nil
+		}))
+		if MustResource(h, clientKey) != client {
+			t.Error("expected MustResource to construct client")
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		if clientKey.Name() != "client" || clientKey.String() != "client (*http.Client)" {
+			t.Errorf("unexpected key: %s", clientKey)
+		}
+	})
+}
+
+func TestMustResource_Failures(t *testing.T) {
+	t.Run("Missing", func(t *testing.T) {
+		mockT := &fatalfTB{TB: t}
+		MustResource(New(mockT), clientKey)
+		if len(mockT.messages) != 1 || mockT.messages[0] != `resource "client" is missing` {
+			t.Errorf("unexpected failure: %v", mockT.messages)
+		}
+	})
+
+	t.Run("WrongType", func(t *testing.T) {
+		mockT := &fatalfTB{TB: t}
+		h := New(mockT, WithResource("client", "not a client", nil))
+		MustResource(h, clientKey)
+		want := `resource "client" has wrong type: want *http.Client, got string`
+		if len(mockT.messages) != 1 || mockT.messages[0] != want {
+			t.Errorf("unexpected failure: %v", mockT.messages)
+		}
+	})
+}
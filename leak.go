@@ -0,0 +1,109 @@
+package testkit
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/next-trace/scg-test-kit/internal/leak"
+)
+
+// DefaultLeakGracePeriod is how long leak checks wait for goroutines to exit.
+const DefaultLeakGracePeriod = time.Second
+
+// LeakOption configures a leak check.
+type LeakOption func(*leakConfig)
+
+type leakConfig struct {
+	grace  time.Duration
+	ignore []string
+}
+
+func newLeakConfig(opts []LeakOption) leakConfig {
+	cfg := leakConfig{grace: DefaultLeakGracePeriod}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// LeakGracePeriod sets how long the check keeps retrying before reporting a leak.
+func LeakGracePeriod(d time.Duration) LeakOption {
+	return func(c *leakConfig) {
+		c.grace = d
+	}
+}
+
+// IgnoreGoroutines ignores goroutines whose stack contains any of patterns,
+// typically a function name such as "github.com/acme/metrics.(*Flusher).loop".
+// The patterns extend the built-in list, which covers net/http keep-alive loops.
+func IgnoreGoroutines(patterns ...string) LeakOption {
+	return func(c *leakConfig) {
+		c.ignore = append(c.ignore, patterns...)
+	}
+}
+
+// WithGoroutineLeakCheck snapshots the running goroutines and, after all resource
+// cleanups have run, fails the test listing the stacks of goroutines started since
+// that are still running once the grace period has elapsed. Pass it as the first
+// option so that goroutines of other resources are covered. Goroutines are
+// process-wide, so those of tests running concurrently with t.Parallel are reported
+// as leaks; avoid it in such tests.
+func WithGoroutineLeakCheck(opts ...LeakOption) Option {
+	return func(h *Harness) {
+		cfg := newLeakConfig(opts)
+		ignore := append(append([]string(nil), leak.DefaultIgnores...), cfg.ignore...)
+		baseline := leak.Take()
+		h.AddCheck(func() error {
+			return leak.CheckGoroutines(baseline, ignore, cfg.grace)
+		})
+	}
+}
+
+// WithFDLeakCheck records the open file descriptors and, after all resource cleanups
+// have run, fails the test listing leaks by kind and target: descriptors opened since
+// that are still open (Linux only, via /proc/self/fd), response bodies from harness-owned
+// HTTP servers that were never closed, and connections still serving a request when
+// such a server shut down. Pass it as the first option so that every resource is covered.
+// Descriptors are process-wide, so avoid it in tests that run with t.Parallel.
+func WithFDLeakCheck(opts ...LeakOption) Option {
+	return func(h *Harness) {
+		cfg := newLeakConfig(opts)
+		baseline, err := leak.TakeFDs()
+		if err != nil && !errors.Is(err, leak.ErrUnsupported) {
+			h.T().Fatalf("fd leak check: %v", err)
+			return
+		}
+		h.AddCheck(func() error {
+			return leak.CheckResources(baseline, func() []leak.Resource { return openConnections(h) }, cfg.grace)
+		})
+	}
+}
+
+// connectionTracker is implemented by harness-owned HTTP servers.
+type connectionTracker interface {
+	OpenBodies() []string
+	ActiveConns() []string
+}
+
+// openConnections lists unclosed response bodies and in-flight connections of the
+// HTTP servers registered on h.
+func openConnections(h *Harness) []leak.Resource {
+	var open []leak.Resource
+	for name, value := range h.Values() {
+		tracker, ok := value.(connectionTracker)
+		if !ok {
+			continue
+		}
+		for _, req := range tracker.OpenBodies() {
+			open = append(open, leak.Resource{Kind: "http-body", Target: fmt.Sprintf("%s %s: response body not closed", name, req)})
+		}
+		for _, addr := range tracker.ActiveConns() {
+			open = append(open, leak.Resource{Kind: "http-conn", Target: fmt.Sprintf("%s %s: request still in flight at shutdown", name, addr)})
+		}
+	}
+	slices.SortFunc(open, func(a, b leak.Resource) int { return strings.Compare(a.Target, b.Target) })
+	return open
+}
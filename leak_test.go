@@ -0,0 +1,87 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type errorfTB struct {
+	testing.TB
+	errors []string
+}
+
+func (m *errorfTB) Errorf(format string, args ...any) {
+	m.errors = append(m.errors, fmt.Sprintf(format, args...))
+}
+
+func TestWithGoroutineLeakCheck(t *testing.T) {
+	t.Run("HTTPServerIsClean", func(t *testing.T) {
+		h := New(t, WithGoroutineLeakCheck(), WithHTTPServer(http.NotFoundHandler()))
+		Get(t, h, "/", nil)
+	})
+
+	t.Run("Leak", func(t *testing.T) {
+		mockT := &errorfTB{TB: t}
+		stop := make(chan struct{})
+		defer close(stop)
+
+		h := New(mockT, WithGoroutineLeakCheck(LeakGracePeriod(20*time.Millisecond)))
+		go func() { <-stop }()
+		h.Cleanup()
+
+		if len(mockT.errors) != 1 || !strings.Contains(mockT.errors[0], "found 1 leaked goroutine(s)") {
+			t.Errorf("expected leaked goroutine to be reported, got %v", mockT.errors)
+		}
+	})
+
+	t.Run("Ignored", func(t *testing.T) {
+		mockT := &errorfTB{TB: t}
+		stop := make(chan struct{})
+		defer close(stop)
+
+		h := New(mockT, WithGoroutineLeakCheck(
+			LeakGracePeriod(20*time.Millisecond),
+			IgnoreGoroutines("TestWithGoroutineLeakCheck"),
+		))
+		go func() { <-stop }()
+		h.Cleanup()
+
+		if len(mockT.errors) != 0 {
+			t.Errorf("expected ignored goroutine not to be reported, got %v", mockT.errors)
+		}
+	})
+}
+
+func TestWithFDLeakCheck(t *testing.T) {
+	t.Run("Clean", func(t *testing.T) {
+		h := New(t, WithFDLeakCheck(), WithHTTPServer(http.NotFoundHandler()))
+		Get(t, h, "/", nil)
+	})
+
+	t.Run("UnclosedBody", func(t *testing.T) {
+		mockT := &errorfTB{TB: t}
+		h := New(mockT, WithFDLeakCheck(LeakGracePeriod(20*time.Millisecond)), WithHTTPServer(http.NotFoundHandler()))
+
+		srv, _ := Resource[interface {
+			BaseURL() string
+			Client() *http.Client
+		}](h, HTTPResourceName)
+		resp, err := srv.Client().Get(srv.BaseURL() + "/forgotten")
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Cleanup()
+		_ = resp.Body.Close()
+
+		if len(mockT.errors) != 1 {
+			t.Fatalf("expected a single leak report, got %v", mockT.errors)
+		}
+		want := "http-body: HTTPServer GET /forgotten: response body not closed"
+		if !strings.Contains(mockT.errors[0], want) {
+			t.Errorf("expected report to contain %q:\n%s", want, mockT.errors[0])
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package testkit
+
+import (
+	"log/slog"
+
+	"github.com/next-trace/scg-test-kit/internal/logcapture"
+)
+
+// LoggerResourceName is the name used to store the capturing logger in harness resources.
+const LoggerResourceName = "Logger"
+
+// logCaptureResourceName stores the Capture behind the logger.
+const logCaptureResourceName = "LogCapture"
+
+// LogCapture holds the records logged through the harness logger; see WithLogCapture.
+type LogCapture = logcapture.Capture
+
+// LogRecord is a captured log record. Attributes inside groups have dotted keys.
+type LogRecord = logcapture.Record
+
+// WithLogCapture registers an *slog.Logger that buffers every record instead of
+// writing it. If the test fails, including in a cleanup or check such as a mock
+// server's verification, the records are written to the test log once the harness
+// has cleaned up, so each failure shows only its own logs:
+//
+//	h := testkit.New(t, testkit.WithLogCapture())
+//	svc := NewService(testkit.Logger(h))
+//	// ...
+//	testkit.Logs(h).ExpectLog(slog.LevelWarn, "retrying", "attempt", 2)
+//	testkit.Logs(h).NoErrorsLogged()
+func WithLogCapture() Option {
+	return func(h *Harness) {
+		t := h.T()
+		capture := logcapture.New(t, slog.LevelDebug)
+		h.SetResource(logCaptureResourceName, capture, nil)
+		// Finalizers run after cleanups and checks, so their failures are seen too
+		h.AddFinalizer(func() {
+			if t.Failed() {
+				t.Logf("captured logs:\n%s", capture.Dump())
+			}
+		})
+		h.SetResource(LoggerResourceName, capture.Logger(), nil, DependsOn(logCaptureResourceName))
+	}
+}
+
+// Logger returns the logger registered with WithLogCapture, failing the test if there is none.
+func Logger(h *Harness) *slog.Logger {
+	h.T().Helper()
+	return MustResource(h, NewKey[*slog.Logger](LoggerResourceName))
+}
+
+// Logs returns the records captured by the WithLogCapture logger, failing the test if
+// there is none. Assertion failures are reported to h's test, also for a child harness.
+func Logs(h *Harness) *LogCapture {
+	h.T().Helper()
+	capture := MustResource(h, NewKey[*LogCapture](logCaptureResourceName))
+	if capture == nil {
+		return nil
+	}
+	return capture.WithT(h.T())
+}
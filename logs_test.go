@@ -0,0 +1,96 @@
+package testkit
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// failedTB is a failed test whose cleanups are run by hand.
+type failedTB struct {
+	testing.TB
+	cleanups []func()
+	logs     []string
+}
+
+func (m *failedTB) Failed() bool     { return true }
+func (m *failedTB) Cleanup(f func()) { m.cleanups = append(m.cleanups, f) }
+func (m *failedTB) Logf(format string, args ...any) {
+	m.logs = append(m.logs, fmt.Sprintf(format, args...))
+}
+func (m *failedTB) Errorf(format string, args ...any) {
+	m.logs = append(m.logs, fmt.Sprintf(format, args...))
+}
+
+// failingTB is a test that fails once Errorf is called.
+type failingTB struct {
+	failedTB
+	failed bool
+}
+
+func (m *failingTB) Failed() bool { return m.failed }
+
+func (m *failingTB) Errorf(format string, args ...any) {
+	m.failed = true
+	m.failedTB.Errorf(format, args...)
+}
+
+func (m *failedTB) runCleanups() {
+	for i := len(m.cleanups) - 1; i >= 0; i-- {
+		m.cleanups[i]()
+	}
+}
+
+func TestWithLogCapture(t *testing.T) {
+	t.Run("Assertions", func(t *testing.T) {
+		h := New(t, WithLogCapture())
+		Logger(h).With("component", "worker").Warn("retrying", "attempt", 2)
+
+		Logs(h).ExpectLog(slog.LevelWarn, "retry", "component", "worker", "attempt", 2)
+		Logs(h).NoErrorsLogged()
+
+		t.Run("Sub", func(t *testing.T) {
+			mtb := &errorfTB{TB: t}
+			sub := Sub(h, mtb)
+			Logger(sub).Error("from subtest")
+			Logs(sub).NoErrorsLogged()
+			if len(mtb.errors) != 1 || !strings.Contains(mtb.errors[0], "from subtest") {
+				t.Errorf("expected child harness to share the capture and report to its test, got %v", mtb.errors)
+			}
+		})
+	})
+
+	t.Run("DumpedOnFailure", func(t *testing.T) {
+		mtb := &failedTB{TB: t}
+		h := New(mtb, WithLogCapture())
+		Logger(h).Info("request handled", "status", 500)
+		mtb.runCleanups()
+
+		if len(mtb.logs) != 1 || !strings.Contains(mtb.logs[0], "captured logs:") ||
+			!strings.Contains(mtb.logs[0], "INFO request handled status=500") {
+			t.Errorf("expected logs to be dumped for the failed test, got %v", mtb.logs)
+		}
+	})
+
+	t.Run("DumpedOnCheckFailure", func(t *testing.T) {
+		mtb := &failingTB{failedTB: failedTB{TB: t}}
+		h := New(mtb, WithLogCapture(), WithMockServer("upstream"))
+		MockServer(h, "upstream").Expect("GET", "/never").Once()
+		Logger(h).Warn("upstream skipped")
+		mtb.runCleanups()
+
+		if len(mtb.logs) != 2 || !strings.Contains(mtb.logs[0], "mock server upstream") ||
+			!strings.Contains(mtb.logs[1], "WARN upstream skipped") {
+			t.Errorf("expected logs to be dumped after the failing check, got %v", mtb.logs)
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		mtb := &fatalfTB{TB: t}
+		h := New(mtb)
+		if Logger(h) != nil || Logs(h) != nil || len(mtb.messages) != 2 {
+			t.Errorf("expected missing capture to fail, got %v", mtb.messages)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package testkit
+
+import (
+	"fmt"
+
+	http_internal "github.com/next-trace/scg-test-kit/internal/http"
+)
+
+// Mock is a programmable mock upstream server; see WithMockServer.
+type Mock = http_internal.Mock
+
+// Expectation is a request a Mock expects and the response it answers.
+type Expectation = http_internal.Expectation
+
+// MockRequest is a request received by a Mock, also used as response template data.
+type MockRequest = http_internal.MockRequest
+
+// WithMockServer registers a mock upstream server under name. Declare expectations
+// on it with MockServer and point the code under test at its BaseURL:
+//
+//	h := testkit.New(t, testkit.WithMockServer("billing"))
+//	billing := testkit.MockServer(h, "billing")
+//	billing.Expect("POST", "/invoices").WithJSON(`{"amount": 10}`).Respond(201, `{"id": 1}`).Once()
+//	svc := NewService(billing.BaseURL())
+//
+// When the harness cleans up, unexpected calls, calls beyond their count or out of
+// order, and unmet expectations fail the test.
+func WithMockServer(name string, opts ...HTTPServerOption) Option {
+	return func(h *Harness) {
+		h.T().Helper()
+		m, cleanup := http_internal.NewMock(h.T(), opts...)
+		if err := h.AddResource(name, m, cleanup); err != nil {
+			h.T().Fatalf("resource %s: %v", name, err)
+			return
+		}
+		// Checks run after all cleanups, so calls still in flight are counted
+		h.AddCheck(func() error {
+			if err := m.Verify(); err != nil {
+				return fmt.Errorf("mock server %s:\n%w", name, err)
+			}
+			return nil
+		})
+	}
+}
+
+// MockServer returns the mock registered under name, failing the test if there is none.
+func MockServer(h *Harness, name string) *Mock {
+	h.T().Helper()
+	return MustResource(h, NewKey[*Mock](name))
+}
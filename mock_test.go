@@ -0,0 +1,42 @@
+package testkit
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithMockServer(t *testing.T) {
+	t.Run("Met", func(t *testing.T) {
+		h := New(t, WithMockServer("billing", InProcess()))
+		billing := MockServer(h, "billing")
+		billing.Expect("POST", "/invoices").WithJSON(`{"amount": 10}`).Respond(http.StatusCreated, `{"id": 1}`).Once()
+
+		resp, err := billing.Client().Post(billing.BaseURL()+"/invoices", "application/json", strings.NewReader(`{"amount":10}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("expected programmed status, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("UnmetFailsAtCleanup", func(t *testing.T) {
+		mockT := &errorfTB{TB: t}
+		h := New(mockT, WithMockServer("billing"))
+		MockServer(h, "billing").Expect("GET", "/health").Once()
+		h.Cleanup()
+
+		if len(mockT.errors) != 1 || !strings.Contains(mockT.errors[0], "mock server billing:\nGET /health: called 0 time(s), expected exactly 1 time(s)") {
+			t.Errorf("expected unmet expectation to be reported, got %q", mockT.errors)
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		mockT := &mockTB{TB: t}
+		if MockServer(New(mockT), "billing") != nil || !mockT.failed {
+			t.Error("expected missing mock server to fail the test")
+		}
+	})
+}
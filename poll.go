@@ -0,0 +1,27 @@
+package testkit
+
+import (
+	"time"
+
+	"github.com/next-trace/scg-test-kit/internal/harness"
+)
+
+// PollOption configures h.Eventually and h.Consistently.
+type PollOption = harness.PollOption
+
+// PollInterval polls at a fixed interval instead of backing off.
+func PollInterval(d time.Duration) PollOption {
+	return harness.PollInterval(d)
+}
+
+// PollBackoff sets the delay after the first attempt and the cap it doubles up to.
+// The default is 10ms doubling up to 200ms.
+func PollBackoff(initial, maxDelay time.Duration) PollOption {
+	return harness.PollBackoff(initial, maxDelay)
+}
+
+// PollTimeout sets how long Eventually waits, 5s by default, or how long Consistently
+// checks, 500ms by default. Either is shortened to finish before the test deadline.
+func PollTimeout(d time.Duration) PollOption {
+	return harness.PollTimeout(d)
+}
@@ -0,0 +1,56 @@
+package testkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEventually(t *testing.T) {
+	t.Run("WaitsForCondition", func(t *testing.T) {
+		h := New(t)
+		var ready atomic.Bool
+		time.AfterFunc(20*time.Millisecond, func() { ready.Store(true) })
+
+		ok := h.Eventually(func(context.Context) error {
+			if !ready.Load() {
+				return errors.New("not ready")
+			}
+			return nil
+		}, PollBackoff(time.Millisecond, 5*time.Millisecond))
+		if !ok {
+			t.Error("expected condition to become true")
+		}
+	})
+
+	t.Run("Fails", func(t *testing.T) {
+		mtb := &errorfTB{TB: t}
+		h := New(mtb)
+		h.Eventually(func(context.Context) error {
+			return errors.New("queue empty")
+		}, PollInterval(time.Millisecond), PollTimeout(10*time.Millisecond))
+
+		if len(mtb.errors) != 1 || !strings.Contains(mtb.errors[0], "queue empty") {
+			t.Errorf("expected last error to be reported, got %v", mtb.errors)
+		}
+	})
+}
+
+func TestConsistently(t *testing.T) {
+	mtb := &errorfTB{TB: t}
+	h := New(mtb)
+	var calls atomic.Int32
+	h.Consistently(func(context.Context) error {
+		if calls.Add(1) > 2 {
+			return errors.New("cache evicted")
+		}
+		return nil
+	}, PollInterval(time.Millisecond), PollTimeout(time.Second))
+
+	if len(mtb.errors) != 1 || !strings.Contains(mtb.errors[0], "cache evicted") {
+		t.Errorf("expected failure to be reported, got %v", mtb.errors)
+	}
+}
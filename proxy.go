@@ -0,0 +1,80 @@
+package testkit
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/next-trace/scg-test-kit/internal/proxy"
+)
+
+// TCPProxy is an in-process TCP proxy that injects faults; see WithTCPProxy.
+type TCPProxy = proxy.TCP
+
+// HTTPProxy is an in-process reverse proxy that injects faults; see WithHTTPProxy.
+type HTTPProxy = proxy.HTTP
+
+// WithTCPProxy registers under name a TCP proxy in front of target ("host:port").
+// Point the code under test at its Addr and inject faults while the test runs:
+//
+//	h := testkit.New(t, testkit.WithTCPProxy("db", dbAddr))
+//	db := testkit.TCPProxyFor(h, "db")
+//	connect(db.Addr())
+//	db.Latency(200*time.Millisecond, 50*time.Millisecond)
+//	// ... exercise timeouts ...
+//	db.Heal()
+func WithTCPProxy(name, target string, opts ...ResourceOption) Option {
+	return func(h *Harness) {
+		h.T().Helper()
+		p, err := proxy.NewTCP(target)
+		if err != nil {
+			h.T().Fatalf("proxy %s: %v", name, err)
+			return
+		}
+		if err := h.AddResource(name, p, p.Close, opts...); err != nil {
+			_ = p.Close()
+			h.T().Fatalf("resource %s: %v", name, err)
+		}
+	}
+}
+
+// WithHTTPProxy registers under name an HTTP reverse proxy in front of the target
+// base URL. An empty target proxies the HTTPServer resource, which must be
+// registered first; the proxy then depends on it and trusts its certificate.
+func WithHTTPProxy(name, target string, opts ...ResourceOption) Option {
+	return func(h *Harness) {
+		t := h.T()
+		t.Helper()
+		// The Option may be applied more than once, so the captured arguments stay untouched
+		upstream, resourceOpts := target, opts
+		var transport http.RoundTripper
+		if upstream == "" {
+			srv, ok := lookupHTTPServer(t, h)
+			if !ok {
+				return
+			}
+			upstream, transport = srv.BaseURL(), srv.Client().Transport
+			resourceOpts = append(slices.Clip(opts), DependsOn(HTTPResourceName))
+		}
+		p, err := proxy.NewHTTP(upstream, transport)
+		if err != nil {
+			t.Fatalf("proxy %s: %v", name, err)
+			return
+		}
+		if err := h.AddResource(name, p, p.Close, resourceOpts...); err != nil {
+			_ = p.Close()
+			t.Fatalf("resource %s: %v", name, err)
+		}
+	}
+}
+
+// TCPProxyFor returns the TCP proxy registered under name, failing the test if there is none.
+func TCPProxyFor(h *Harness, name string) *TCPProxy {
+	h.T().Helper()
+	return MustResource(h, NewKey[*TCPProxy](name))
+}
+
+// HTTPProxyFor returns the HTTP proxy registered under name, failing the test if there is none.
+func HTTPProxyFor(h *Harness, name string) *HTTPProxy {
+	h.T().Helper()
+	return MustResource(h, NewKey[*HTTPProxy](name))
+}
@@ -0,0 +1,119 @@
+package testkit
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWithHTTPProxy(t *testing.T) {
+	h := New(t,
+		WithHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		}), TLS()),
+		WithHTTPProxy("api", ""),
+	)
+	api := HTTPProxyFor(h, "api")
+
+	// A client that retries server errors, as the code under test would
+	getWithRetry := func(attempts int) (int, int) {
+		tries := 0
+		for ; tries < attempts; tries++ {
+			resp, err := http.Get(api.URL() + "/")
+			if err != nil {
+				continue
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode < 500 {
+				return resp.StatusCode, tries + 1
+			}
+		}
+		return 0, tries
+	}
+
+	api.StatusTimes(http.StatusBadGateway, 2)
+	if code, tries := getWithRetry(3); code != http.StatusOK || tries != 3 {
+		t.Errorf("expected success on the third try, got %d after %d", code, tries)
+	}
+
+	api.Status(http.StatusServiceUnavailable)
+	if code, _ := getWithRetry(3); code != 0 {
+		t.Errorf("expected retries to be exhausted, got %d", code)
+	}
+	api.Heal()
+
+	client := &http.Client{Timeout: 20 * time.Millisecond}
+	api.Latency(100*time.Millisecond, 0)
+	if _, err := client.Get(api.URL() + "/"); err == nil {
+		t.Error("expected client timeout behind a slow proxy")
+	}
+}
+
+func TestWithHTTPProxy_Reused(t *testing.T) {
+	// One Option applied to several harnesses proxies each harness's own server
+	proxyOpt := WithHTTPProxy("api", "")
+	for _, body := range []string{"first", "second"} {
+		h := New(t,
+			WithHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(body))
+			})),
+			proxyOpt,
+		)
+		resp, err := http.Get(HTTPProxyFor(h, "api").URL() + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(got) != body {
+			t.Errorf("expected proxy to reach the %s server, got %q", body, got)
+		}
+		h.Cleanup()
+	}
+}
+
+func TestWithTCPProxy(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("hello"))
+			_ = conn.Close()
+		}
+	}()
+
+	h := New(t, WithTCPProxy("upstream", ln.Addr().String()))
+	p := TCPProxyFor(h, "upstream")
+	read := func() (string, error) {
+		conn, err := net.Dial("tcp", p.Addr())
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = conn.Close() }()
+		data, err := io.ReadAll(conn)
+		return string(data), err
+	}
+
+	if got, err := read(); err != nil || got != "hello" {
+		t.Errorf("expected data through the proxy, got %q (%v)", got, err)
+	}
+	p.Truncate(2)
+	if got, _ := read(); got != "he" {
+		t.Errorf("expected truncated data, got %q", got)
+	}
+	p.Heal()
+
+	mockT := &mockTB{TB: t}
+	if TCPProxyFor(New(mockT), "upstream") != nil || !mockT.failed {
+		t.Error("expected missing proxy to fail the test")
+	}
+}
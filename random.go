@@ -0,0 +1,59 @@
+package testkit
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/next-trace/scg-test-kit/internal/random"
+)
+
+// SeedEnv overrides the seed of every harness random source, e.g. to reproduce a failure.
+const SeedEnv = random.SeedEnv
+
+const randomResourcePrefix = "random:"
+
+// Rand is a deterministic source of fake data: names, emails, UUIDs, timestamps.
+type Rand = random.Rand
+
+// Random returns the random source of h's test, creating it on first use. The seed is
+// derived from the test name, so a test sees the same values on every run, even when
+// run alone, unless TESTKIT_SEED is set. If the test fails, the seed is logged with
+// the setting that reproduces it:
+//
+//	r := testkit.Random(h)
+//	user := User{ID: r.UUID(), Name: r.Name(), Email: r.Email()}
+//
+// Child harnesses of subtests get their own source, seeded from the subtest name.
+func Random(h *Harness) *Rand {
+	t := h.T()
+	t.Helper()
+	// Keyed by test name, so a child harness does not reuse its parent's source
+	key := randomResourcePrefix + t.Name()
+	if err := h.EnsureProvider(key, func(*Harness) (any, func() error, error) {
+		seed := random.SeedFor(t.Name())
+		if env := os.Getenv(SeedEnv); env != "" {
+			var err error
+			if seed, err = strconv.ParseUint(env, 10, 64); err != nil {
+				return nil, nil, fmt.Errorf("invalid %s %q: %w", SeedEnv, env, err)
+			}
+		}
+		// Logged once cleanups and checks have run, so their failures are covered too
+		h.AddFinalizer(func() {
+			if t.Failed() {
+				t.Logf("random seed %d, reproduce with %s=%d", seed, SeedEnv, seed)
+			}
+		})
+		return random.New(seed), nil, nil
+	}); err != nil {
+		t.Fatalf("random source: %v", err)
+		return nil
+	}
+	r, _ := Resource[*Rand](h, key)
+	return r
+}
+
+// Pick returns a random element of items. It panics if items is empty.
+func Pick[T any](r *Rand, items ...T) T {
+	return random.Pick(r, items)
+}
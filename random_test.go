@@ -0,0 +1,69 @@
+package testkit
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRandom(t *testing.T) {
+	t.Run("StablePerTest", func(t *testing.T) {
+		h := New(t)
+		first := Random(h)
+		if Random(h) != first {
+			t.Error("expected the source to be reused within a test")
+		}
+		again := New(t)
+		if Random(again).UUID() != first.UUID() {
+			t.Error("expected the same test name to yield the same values")
+		}
+
+		t.Run("Child", func(t *testing.T) {
+			if Random(Sub(h, t)).Seed() == first.Seed() {
+				t.Error("expected a subtest to get its own seed")
+			}
+		})
+	})
+
+	t.Run("SeedFromEnv", func(t *testing.T) {
+		t.Setenv(SeedEnv, "1234")
+		if seed := Random(New(t)).Seed(); seed != 1234 {
+			t.Errorf("expected seed from %s, got %d", SeedEnv, seed)
+		}
+
+		t.Setenv(SeedEnv, "abc")
+		mtb := &fatalfTB{TB: t}
+		if Random(New(mtb)) != nil || len(mtb.messages) != 1 {
+			t.Errorf("expected invalid seed to fail, got %v", mtb.messages)
+		}
+	})
+
+	t.Run("SeedLoggedOnFailure", func(t *testing.T) {
+		mtb := &failedTB{TB: t}
+		h := New(mtb)
+		seed := Random(h).Seed()
+		mtb.runCleanups()
+		if len(mtb.logs) != 1 || !strings.Contains(mtb.logs[0], SeedEnv+"=") ||
+			!strings.Contains(mtb.logs[0], strconv.FormatUint(seed, 10)) {
+			t.Errorf("expected seed to be logged, got %v", mtb.logs)
+		}
+	})
+
+	t.Run("SeedLoggedOnCheckFailure", func(t *testing.T) {
+		mtb := &failingTB{failedTB: failedTB{TB: t}}
+		h := New(mtb, WithMockServer("upstream"))
+		MockServer(h, "upstream").Expect("GET", "/never").Once()
+		Random(h)
+		mtb.runCleanups()
+		if len(mtb.logs) != 2 || !strings.Contains(mtb.logs[1], SeedEnv+"=") {
+			t.Errorf("expected seed to be logged after the failing check, got %v", mtb.logs)
+		}
+	})
+
+	t.Run("Pick", func(t *testing.T) {
+		r := Random(New(t))
+		if got := Pick(r, "red", "green"); got != "red" && got != "green" {
+			t.Errorf("unexpected pick %q", got)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package testkit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-test-kit/internal/harness"
+	"github.com/next-trace/scg-test-kit/internal/probe"
+)
+
+// ProbeOption configures how a readiness probe is retried.
+type ProbeOption func(*probeConfig)
+
+type probeConfig struct {
+	backoff probe.Backoff
+	timeout time.Duration
+}
+
+// ProbeBackoff sets the delay after the first failed attempt and the cap it doubles up to.
+// The default is 50ms doubling up to 1s.
+func ProbeBackoff(initial, maxDelay time.Duration) ProbeOption {
+	return func(c *probeConfig) {
+		c.backoff = probe.Backoff{Initial: initial, Max: maxDelay}
+	}
+}
+
+// ProbeTimeout sets the overall deadline for the resource to become ready. The default is 30s.
+func ProbeTimeout(d time.Duration) ProbeOption {
+	return func(c *probeConfig) {
+		c.timeout = d
+	}
+}
+
+// ReadyWhen attaches a readiness probe to a resource. New and the presets block until
+// every probe succeeds, and fail the test naming each resource that never became ready.
+// A nil error from probe means the resource is ready.
+func ReadyWhen(probe func(ctx context.Context) error, opts ...ProbeOption) ResourceOption {
+	var cfg probeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return harness.ReadyWhen(probe, cfg.backoff, cfg.timeout)
+}
+
+// TCPProbe returns a readiness probe that succeeds once addr accepts TCP connections.
+func TCPProbe(addr string) func(ctx context.Context) error {
+	return probe.TCP(addr)
+}
+
+// HTTPProbe returns a readiness probe that succeeds once a GET to url answers with one
+// of statuses, or with any 2xx status if none are given.
+func HTTPProbe(url string, statuses ...int) func(ctx context.Context) error {
+	return probe.HTTP(http.DefaultClient, url, statuses...)
+}
+
+// waitReady fails the test if any resource registered on h never became ready.
+func waitReady(t testing.TB, h *Harness) {
+	t.Helper()
+	if err := h.WaitReady(); err != nil {
+		t.Fatalf("resources not ready:\n%v", err)
+	}
+}
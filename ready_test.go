@@ -0,0 +1,48 @@
+package testkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fatalfTB struct {
+	testing.TB
+	messages []string
+}
+
+func (m *fatalfTB) Fatalf(format string, args ...any) {
+	m.messages = append(m.messages, fmt.Sprintf(format, args...))
+}
+
+func TestReadyWhen(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	t.Run("Probes", func(t *testing.T) {
+		h := New(t,
+			WithResource("tcp", srv, nil, ReadyWhen(TCPProbe(srv.Listener.Addr().String()))),
+			WithResource("http", srv, nil, ReadyWhen(HTTPProbe(srv.URL+"/health", http.StatusOK))),
+		)
+		if _, ok := h.Resource("http"); !ok {
+			t.Error("expected resource to be registered")
+		}
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		mockT := &fatalfTB{TB: t}
+		New(mockT, WithResource("queue", nil, nil, ReadyWhen(
+			func(context.Context) error { return errors.New("connection refused") },
+			ProbeBackoff(time.Millisecond, 5*time.Millisecond),
+			ProbeTimeout(20*time.Millisecond),
+		)))
+		if len(mockT.messages) != 1 || !strings.Contains(mockT.messages[0], "resource queue: not ready after") {
+			t.Errorf("expected readiness failure, got %v", mockT.messages)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package testkit
+
+import (
+	"regexp"
+
+	"github.com/next-trace/scg-test-kit/internal/snapshot"
+)
+
+// SnapshotOption configures a snapshot comparison.
+type SnapshotOption = snapshot.Option
+
+// SnapshotUpdateEnv regenerates snapshots when set to a true value. A boolean
+// -update flag defined by the test binary has the same effect.
+const SnapshotUpdateEnv = snapshot.UpdateEnv
+
+// MatchSnapshot compares value with a golden file under testdata/snapshots named
+// after the current test, failing with a unified diff on mismatch:
+//
+//	testkit.MatchSnapshot(h, order, testkit.ScrubUUIDs(), testkit.ScrubFields("createdAt"))
+//
+// Strings and byte slices are compared as text, other values as indented JSON with
+// sorted keys. A missing snapshot is written on first run, unless the CI
+// environment variable is set. Use Response.MatchSnapshot for HTTP responses.
+func MatchSnapshot(h *Harness, value any, opts ...SnapshotOption) {
+	t := h.T()
+	t.Helper()
+	snapshot.Match(t, value, opts...)
+}
+
+// SnapshotName distinguishes several snapshots taken by one test; unnamed ones are numbered.
+func SnapshotName(name string) SnapshotOption { return snapshot.Name(name) }
+
+// ScrubUUIDs replaces UUIDs with <uuid> before comparing.
+func ScrubUUIDs() SnapshotOption { return snapshot.UUIDs() }
+
+// ScrubTimestamps replaces RFC 3339 style timestamps with <timestamp> before comparing.
+func ScrubTimestamps() SnapshotOption { return snapshot.Timestamps() }
+
+// ScrubFields replaces the values of the named JSON keys, at any depth, with "<redacted>".
+func ScrubFields(names ...string) SnapshotOption { return snapshot.Fields(names...) }
+
+// ScrubRegexp replaces every match of re with replacement before comparing.
+func ScrubRegexp(re *regexp.Regexp, replacement string) SnapshotOption {
+	return snapshot.Regexp(re, replacement)
+}
+
+// Scrub rewrites the normalised output with fn before comparing.
+func Scrub(fn func(string) string) SnapshotOption { return snapshot.Redact(fn) }
@@ -0,0 +1,33 @@
+package testkit
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchSnapshot(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("CI", "")
+	t.Setenv(SnapshotUpdateEnv, "")
+
+	h := New(t, WithHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"0b8e4a62-8c3a-4a54-8f7e-2f4b1b0e3c11","name":"widget","at":"2024-05-22T10:00:00+02:00"}`))
+	}), InProcess()))
+
+	HTTP(h).Get("/items/1").Do().MatchSnapshot(ScrubUUIDs(), ScrubTimestamps())
+	// The second call compares against the snapshot written by the first
+	MatchSnapshot(h, []string{"b", "a"}, SnapshotName("list"))
+	MatchSnapshot(h, []string{"b", "a"}, SnapshotName("list"))
+
+	data, err := os.ReadFile(filepath.Join("testdata", "snapshots", "TestMatchSnapshot.snap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"at\": \"<timestamp>\",\n  \"id\": \"<uuid>\",\n  \"name\": \"widget\"\n}\n"
+	if string(data) != want {
+		t.Errorf("unexpected snapshot:\n%s", data)
+	}
+}
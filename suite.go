@@ -0,0 +1,37 @@
+package testkit
+
+import (
+	"github.com/next-trace/scg-test-kit/internal/harness"
+)
+
+// Suite holds expensive resources shared by all tests of a package.
+// Create it in TestMain, register resources with AddResource, and let Run
+// tear them down after m.Run returns:
+//
+//	var suite = testkit.NewSuite()
+//
+//	func TestMain(m *testing.M) {
+//		db := seedDatabase()
+//		if err := suite.AddResource("db", db, db.Close); err != nil {
+//			log.Fatal(err)
+//		}
+//		os.Exit(suite.Run(m))
+//	}
+type Suite = harness.Suite
+
+// Runner runs the tests of a package. It is implemented by *testing.M.
+type Runner = harness.Runner
+
+// NewSuite creates an empty Suite.
+func NewSuite() *Suite {
+	return harness.NewSuite()
+}
+
+// WithSuite lets the harness borrow the shared resources of s through Resource.
+// Borrowed resources are read-only: the harness never tears them down, and local
+// resources registered under the same name shadow them.
+func WithSuite(s *Suite) Option {
+	return func(h *Harness) {
+		h.UseSuite(s)
+	}
+}
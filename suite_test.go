@@ -0,0 +1,48 @@
+package testkit
+
+import (
+	"testing"
+)
+
+func TestSuite_SharedResources(t *testing.T) {
+	suite := NewSuite()
+	closed := false
+	if err := suite.AddResource("db", "shared-db", func() error {
+		closed = true
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Borrow", func(t *testing.T) {
+		h := New(t, WithSuite(suite))
+		val, ok := Resource[string](h, "db")
+		if !ok || val != "shared-db" {
+			t.Fatalf("expected shared-db, got %q (ok=%v)", val, ok)
+		}
+		if refs := suite.Refs("db"); refs != 1 {
+			t.Errorf("expected 1 ref while borrowed, got %d", refs)
+		}
+	})
+
+	t.Run("Shadow", func(t *testing.T) {
+		h := New(t, WithSuite(suite), WithResource("db", "local-db", nil))
+		val, _ := Resource[string](h, "db")
+		if val != "local-db" {
+			t.Errorf("expected local resource to shadow shared one, got %q", val)
+		}
+	})
+
+	if refs := suite.Refs("db"); refs != 0 {
+		t.Errorf("expected refs to be released after subtests, got %d", refs)
+	}
+	if closed {
+		t.Fatal("expected suite resource to outlive per-test harnesses")
+	}
+	if err := suite.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+	if !closed {
+		t.Error("expected suite to tear down its resource")
+	}
+}
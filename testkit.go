@@ -1,9 +1,11 @@
 package testkit
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/next-trace/scg-test-kit/internal/harness"
 	http_internal "github.com/next-trace/scg-test-kit/internal/http"
@@ -15,17 +17,37 @@ type Harness = harness.Harness
 // Option configures the Harness.
 type Option func(*Harness)
 
+// ResourceOption configures how a resource is registered.
+type ResourceOption = harness.ResourceOption
+
+// CleanupTimeout bounds how long a resource's cleanup may run before the harness
+// reports it as timed out and moves on to the next cleanup.
+func CleanupTimeout(d time.Duration) ResourceOption {
+	return harness.CleanupTimeout(d)
+}
+
+// WithCleanupTimeout bounds the total time the harness may spend running cleanups.
+// Cleanups not yet started when it runs out are skipped and reported, so none keeps
+// running after the harness is torn down.
+func WithCleanupTimeout(d time.Duration) Option {
+	return func(h *Harness) {
+		h.SetCleanupTimeout(d)
+	}
+}
+
 // HTTPResourceName is the name used to store the HTTP server in harness resources.
 const HTTPResourceName = "HTTPServer"
 
 // New creates a new Harness with the given options.
-// It automatically registers cleanup with the testing.TB.
+// It automatically registers cleanup with the testing.TB, constructs Eager providers
+// concurrently, and blocks until the readiness probes of all resources pass.
 func New(t testing.TB, opts ...Option) *Harness {
 	t.Helper()
 	h := harness.New(t)
 	for _, opt := range opts {
 		opt(h)
 	}
+	start(t, h)
 	return h
 }
 
@@ -50,7 +72,21 @@ func NewIntegrationHarness(t testing.TB, opts ...Option) *Harness {
 	return New(t, opts...)
 }
 
-// NewBrowserHarness creates a Harness for browser-like HTTP tests.
+// Sub creates a child Harness for a t.Run subtest with the given options.
+// The child resolves resources through parent, may shadow them locally,
+// and runs only its own cleanups when the subtest finishes.
+func Sub(parent *Harness, t testing.TB, opts ...Option) *Harness {
+	t.Helper()
+	h := parent.Sub(t)
+	for _, opt := range opts {
+		opt(h)
+	}
+	start(t, h)
+	return h
+}
+
+// NewBrowserHarness creates a Harness for browser-like HTTP tests. Use BrowserSession
+// for clients that keep cookies between requests.
 func NewBrowserHarness(t testing.TB, handler http.Handler, opts ...Option) *Harness {
 	t.Helper()
 	h := New(t, opts...)
@@ -61,14 +97,59 @@ func NewBrowserHarness(t testing.TB, handler http.Handler, opts ...Option) *Harn
 }
 
 // WithResource adds a named resource to the harness.
-func WithResource(name string, value any, cleanup func() error) Option {
+// The test fails immediately if a declared dependency is missing or would create a cycle.
+func WithResource(name string, value any, cleanup func() error, opts ...ResourceOption) Option {
 	return func(h *Harness) {
-		h.SetResource(name, value, cleanup)
+		h.T().Helper()
+		if err := h.AddResource(name, value, cleanup, opts...); err != nil {
+			h.T().Fatalf("resource %s: %v", name, err)
+		}
 	}
 }
 
+// WithResourceContext adds a named resource whose cleanup receives a context.
+// The context is cancelled when CleanupTimeout or WithCleanupTimeout expires.
+func WithResourceContext(name string, value any, cleanup func(context.Context) error, opts ...ResourceOption) Option {
+	return func(h *Harness) {
+		h.T().Helper()
+		if err := h.AddResourceContext(name, value, cleanup, opts...); err != nil {
+			h.T().Fatalf("resource %s: %v", name, err)
+		}
+	}
+}
+
+// WithProvider adds a named resource that is constructed the first time it is requested,
+// for example through Resource. The provider runs at most once and its cleanup is
+// registered only if it actually ran. Construction failures fail the test.
+func WithProvider[T any](name string, provider func(*Harness) (T, func() error, error), opts ...ResourceOption) Option {
+	return func(h *Harness) {
+		h.T().Helper()
+		err := h.AddProvider(name, func(h *Harness) (any, func() error, error) {
+			return provider(h)
+		}, opts...)
+		if err != nil {
+			h.T().Fatalf("resource %s: %v", name, err)
+		}
+	}
+}
+
+// Eager makes a WithProvider resource construct during New instead of on first access.
+// Eager providers start concurrently, each waiting only for the providers it DependsOn.
+// The first failure cancels h.Context() for the others, cleans up what already started,
+// and fails the test.
+func Eager() ResourceOption {
+	return harness.Eager()
+}
+
+// DependsOn declares that a resource depends on other named resources.
+// Dependencies must be registered first and are torn down after their dependents.
+func DependsOn(names ...string) ResourceOption {
+	return harness.DependsOn(names...)
+}
+
 // Resource retrieves a named resource from the harness.
 func Resource[T any](h *Harness, name string) (T, bool) {
+	h.T().Helper()
 	var zero T
 	val, ok := h.Resource(name)
 	if !ok {
@@ -79,13 +160,24 @@ func Resource[T any](h *Harness, name string) (T, bool) {
 }
 
 // WithHTTPServer plugs an HTTP server capability into the harness.
-func WithHTTPServer(handler http.Handler) Option {
+// By default the handler is served on a loopback socket; see InProcess.
+func WithHTTPServer(handler http.Handler, opts ...HTTPServerOption) Option {
 	return func(h *Harness) {
-		server, cleanup := http_internal.NewServer(h.T(), handler)
+		server, cleanup := http_internal.NewServer(h.T(), handler, opts...)
 		h.SetResource(HTTPResourceName, server, cleanup)
 	}
 }
 
+// start constructs eager providers and waits for readiness, failing the test on error.
+func start(t testing.TB, h *Harness) {
+	t.Helper()
+	if err := h.Start(); err != nil {
+		t.Fatalf("startup failed: %v", err)
+		return
+	}
+	waitReady(t, h)
+}
+
 // EncodeJSON encodes the given value into an io.Reader.
 func EncodeJSON(t testing.TB, value any) io.Reader {
 	t.Helper()
@@ -101,17 +193,8 @@ func DecodeJSON(t testing.TB, reader io.Reader, target any) {
 // Get performs a GET request to the given path and decodes the response into the target value.
 func Get(t testing.TB, h *Harness, path string, target any) *http.Response {
 	t.Helper()
-	val, ok := h.Resource(HTTPResourceName)
+	srv, ok := lookupHTTPServer(t, h)
 	if !ok {
-		t.Fatal("HTTPServer resource not available")
-		return nil
-	}
-	srv, ok := val.(interface {
-		BaseURL() string
-		Client() *http.Client
-	})
-	if !ok {
-		t.Fatal("HTTPServer resource does not implement required interface")
 		return nil
 	}
 
@@ -131,17 +214,8 @@ func Get(t testing.TB, h *Harness, path string, target any) *http.Response {
 // Post performs a POST request with a JSON body and decodes the response into the target value.
 func Post(t testing.TB, h *Harness, path string, body any, target any) {
 	t.Helper()
-	val, ok := h.Resource(HTTPResourceName)
-	if !ok {
-		t.Fatal("HTTPServer resource not available")
-		return
-	}
-	srv, ok := val.(interface {
-		BaseURL() string
-		Client() *http.Client
-	})
+	srv, ok := lookupHTTPServer(t, h)
 	if !ok {
-		t.Fatal("HTTPServer resource does not implement required interface")
 		return
 	}
 
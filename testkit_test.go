@@ -1,9 +1,11 @@
 package testkit
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestHarness_Presets(t *testing.T) {
@@ -239,3 +241,99 @@ func (m *mockTB) Fatal(_ ...any) {
 func (m *mockTB) Fatalf(_ string, _ ...any) {
 	m.failed = true
 }
+
+func TestHarness_ResourceDependencies(t *testing.T) {
+	t.Run("DependsOn", func(t *testing.T) {
+		var order []string
+		h := New(t,
+			WithResource("db", "db", func() error {
+				order = append(order, "db")
+				return nil
+			}),
+			WithResource("migrator", "migrator", func() error {
+				order = append(order, "migrator")
+				return nil
+			}, DependsOn("db")),
+		)
+		h.Cleanup()
+		if len(order) != 2 || order[0] != "migrator" || order[1] != "db" {
+			t.Errorf("expected [migrator db], got %v", order)
+		}
+	})
+
+	t.Run("MissingDependency", func(t *testing.T) {
+		mockT := &mockTB{TB: t}
+		WithResource("migrator", "migrator", nil, DependsOn("db"))(New(mockT))
+		if !mockT.failed {
+			t.Error("expected WithResource to fail for a missing dependency")
+		}
+	})
+}
+
+func TestHarness_Provider(t *testing.T) {
+	calls := 0
+	h := New(t, WithProvider("client", func(h *Harness) (*http.Client, func() error, error) {
+		calls++
+		return &http.Client{}, nil, nil
+	}))
+
+	if calls != 0 {
+		t.Fatal("expected provider not to run before first access")
+	}
+	first, ok := Resource[*http.Client](h, "client")
+	if !ok || first == nil {
+		t.Fatal("expected client to be constructed")
+	}
+	second, _ := Resource[*http.Client](h, "client")
+	if first != second || calls != 1 {
+		t.Errorf("expected cached client, provider ran %d times", calls)
+	}
+}
+
+func TestHarness_Sub(t *testing.T) {
+	h := New(t, WithResource("db", "parent", nil))
+
+	t.Run("Shadow", func(t *testing.T) {
+		sub := Sub(h, t, WithResource("db", "child", nil))
+		if val, _ := Resource[string](sub, "db"); val != "child" {
+			t.Errorf("expected child, got %q", val)
+		}
+	})
+
+	t.Run("Inherit", func(t *testing.T) {
+		sub := Sub(h, t)
+		if val, _ := Resource[string](sub, "db"); val != "parent" {
+			t.Errorf("expected parent, got %q", val)
+		}
+	})
+}
+
+func TestHarness_CleanupTimeout(t *testing.T) {
+	var cancelled bool
+	h := New(t,
+		WithCleanupTimeout(time.Second),
+		WithResourceContext("worker", "worker", func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				cancelled = true
+			default:
+			}
+			return nil
+		}, CleanupTimeout(time.Minute)),
+	)
+	h.Cleanup()
+	if cancelled {
+		t.Error("expected context not to be cancelled for a fast cleanup")
+	}
+}
+
+func TestHarness_Eager(t *testing.T) {
+	constructed := false
+	New(t, WithProvider("server", func(*Harness) (string, func() error, error) {
+		constructed = true
+		return "server", nil, nil
+	}, Eager()))
+	if !constructed {
+		t.Error("expected eager provider to be constructed by New")
+	}
+}